@@ -0,0 +1,85 @@
+// Package activity implements activity coefficient (excess Gibbs energy)
+// models for liquid mixtures. The models can be used on their own for γ–φ
+// phase equilibrium or as the GEModel of a cubiceos GE mixing rule.
+package activity
+
+import "math"
+
+// Model defines what makes up an activity coefficient model for a fixed
+// set of components. x is always a vector of mole fractions summing to 1
+// with one entry per component.
+type Model interface {
+	// LnGamma writes ln γ_i at T and x into lnGamma
+	LnGamma(T float64, x, lnGamma []float64)
+	// GE returns the molar excess Gibbs energy as gE/(RT)
+	GE(T float64, x []float64) float64
+	// HE returns the molar excess enthalpy as hE/R, in units of T
+	HE(T float64, x []float64) float64
+	Name() string
+}
+
+// Coeff is a temperature-dependent binary parameter
+//
+//	A + B/T + C ln T + D T
+//
+// the usual extended form of simulator databanks
+type Coeff struct {
+	A, B, C, D float64
+}
+
+// Value returns the parameter at T
+func (c Coeff) Value(T float64) float64 {
+	v := c.A + c.B/T + c.D*T
+	if c.C != 0 {
+		v += c.C * math.Log(T)
+	}
+	return v
+}
+
+// Deriv returns the temperature derivative of the parameter at T
+func (c Coeff) Deriv(T float64) float64 {
+	return -c.B/(T*T) + c.C/T + c.D
+}
+
+// Const returns a temperature-independent Coeff
+func Const(v float64) Coeff { return Coeff{A: v} }
+
+// matrix evaluates c and its temperature derivative at T. A nil c is all
+// zero, the ideal-solution value of every parameter in this package.
+func matrix(c [][]Coeff, T float64, n int) (v, dv [][]float64) {
+	v, dv = square(n), square(n)
+	if c == nil {
+		return v, dv
+	}
+	for i := range n {
+		for j := range n {
+			v[i][j] = c[i][j].Value(T)
+			dv[i][j] = c[i][j].Deriv(T)
+		}
+	}
+	return v, dv
+}
+
+func square(n int) [][]float64 {
+	flat := make([]float64, n*n)
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = flat[i*n : (i+1)*n]
+	}
+	return m
+}
+
+// Ideal is the ideal solution, γ_i = 1
+type Ideal struct{}
+
+func (Ideal) LnGamma(T float64, x, lnGamma []float64) {
+	for i := range x {
+		lnGamma[i] = 0
+	}
+}
+
+func (Ideal) GE(T float64, x []float64) float64 { return 0 }
+
+func (Ideal) HE(T float64, x []float64) float64 { return 0 }
+
+func (Ideal) Name() string { return "ideal" }
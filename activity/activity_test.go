@@ -0,0 +1,163 @@
+package activity
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const calR = 1.98721 //cal/(mol K)
+
+// 1-propanol(1)/water(2) at 60 °C with the Wilson and NRTL parameters of
+// Smith, Van Ness and Abbott (Table 12.5), in cal/mol
+var (
+	propanolWater = Wilson{LnLambda: [][]Coeff{
+		{{}, {A: math.Log(18.07 / 75.14), B: -775.48 / calR}},
+		{{A: math.Log(75.14 / 18.07), B: -1351.90 / calR}, {}},
+	}}
+	propanolWaterNRTL = NRTL{
+		Tau:   [][]Coeff{{{}, {B: 500.40 / calR}}, {{B: 1636.57 / calR}, {}}},
+		Alpha: [][]float64{{0, 0.5081}, {0.5081, 0}},
+	}
+	// acetone(1)/water(2) with UNIFAC r and q and illustrative ln τ
+	acetoneWater = UNIQUAC{
+		R:     []float64{2.5735, 0.92},
+		Q:     []float64{2.336, 1.40},
+		LnTau: [][]Coeff{{{}, {A: -0.8}}, {{A: 0.3}, {}}},
+	}
+)
+
+func TestBinaryLnGamma(t *testing.T) {
+	// reference values from each model's binary closed form at x1 = 0.3,
+	// T = 333.15 K
+	for _, tc := range []struct {
+		m    Model
+		want [2]float64
+	}{
+		{propanolWater, [2]float64{0.7535251508338046, 0.27296651626330437}},
+		{propanolWaterNRTL, [2]float64{0.7024249759625769, 0.2926848820894663}},
+		{acetoneWater, [2]float64{0.5394003477786784, 0.1694609162579982}},
+		{Ideal{}, [2]float64{0, 0}},
+	} {
+		lnGamma := make([]float64, 2)
+		tc.m.LnGamma(333.15, []float64{0.3, 0.7}, lnGamma)
+		for i := range lnGamma {
+			if math.Abs(lnGamma[i]-tc.want[i]) > 1e-12 {
+				t.Errorf("%s: ln γ%d = %.15g, want %.15g", tc.m.Name(), i+1, lnGamma[i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestWilsonInfiniteDilution(t *testing.T) {
+	// ln γ1∞ = 1 - ln Λ12 - Λ21
+	const T = 333.15
+	l12 := math.Exp(propanolWater.LnLambda[0][1].Value(T))
+	l21 := math.Exp(propanolWater.LnLambda[1][0].Value(T))
+	lnGamma := make([]float64, 2)
+	propanolWater.LnGamma(T, []float64{0, 1}, lnGamma)
+	if want := 1 - math.Log(l12) - l21; math.Abs(lnGamma[0]-want) > 1e-12 {
+		t.Errorf("ln γ1∞ = %g, want %g", lnGamma[0], want)
+	}
+}
+
+// temperatureDependent gives every model T-dependent parameters so that
+// HE is not trivially zero
+func temperatureDependent() []Model {
+	tau := [][]Coeff{
+		{{}, {A: 0.2, B: 150, D: 1e-3}, {B: 80}},
+		{{A: -0.1, B: 420, C: 0.05}, {}, {A: 0.1, B: 200}},
+		{{B: -30, D: 2e-4}, {B: 60}, {}},
+	}
+	lnTau := [][]Coeff{{{}, {B: -60}, {B: 20}}, {{B: -180, C: 0.02}, {}, {B: -40}}, {{B: 10}, {B: 35, D: 1e-4}, {}}}
+	return []Model{
+		Wilson{LnLambda: tau},
+		NRTL{Tau: tau, Alpha: [][]float64{{0, 0.3, 0.2}, {0.3, 0, 0.47}, {0.2, 0.47, 0}}},
+		UNIQUAC{R: []float64{2.5735, 0.92, 1.4311}, Q: []float64{2.336, 1.40, 1.432}, LnTau: lnTau},
+	}
+}
+
+func TestGEConsistency(t *testing.T) {
+	x := []float64{0.2, 0.5, 0.3}
+	const T = 320.0
+	for _, m := range temperatureDependent() {
+		lnGamma := make([]float64, len(x))
+		m.LnGamma(T, x, lnGamma)
+		sum := 0.0
+		for i := range x {
+			sum += x[i] * lnGamma[i]
+		}
+		if ge := m.GE(T, x); math.Abs(ge-sum) > 1e-12 {
+			t.Errorf("%s: gE/RT = %g, Σ x ln γ = %g", m.Name(), ge, sum)
+		}
+
+		// hE/R = -T² d(gE/RT)/dT
+		h := 1e-4 * T
+		num := -T * T * (m.GE(T+h, x) - m.GE(T-h, x)) / (2 * h)
+		if he := m.HE(T, x); math.Abs(he-num) > 1e-7*math.Max(1, math.Abs(num)) {
+			t.Errorf("%s: hE/R = %g, numeric %g", m.Name(), he, num)
+		}
+	}
+}
+
+func TestPairsRoundTrip(t *testing.T) {
+	in := `# comment
+wilson propanol water -1.424 -390.2 1.425 -680.3
+NRTL propanol water 0 251.8 0.1 0 0 823.6 0 -1e-3 0.5081
+UNIQUAC acetone water -0.8 0 0.3 0
+`
+	pairs, err := ReadPairs(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Pair{
+		{Model: "Wilson", I: "propanol", J: "water", IJ: Coeff{A: -1.424, B: -390.2}, JI: Coeff{A: 1.425, B: -680.3}},
+		{Model: "NRTL", I: "propanol", J: "water", IJ: Coeff{B: 251.8, C: 0.1}, JI: Coeff{A: 0, B: 823.6, D: -1e-3}, Alpha: 0.5081},
+		{Model: "UNIQUAC", I: "acetone", J: "water", IJ: Coeff{A: -0.8}, JI: Coeff{A: 0.3}},
+	}
+	if !reflect.DeepEqual(pairs, want) {
+		t.Fatalf("ReadPairs = %+v, want %+v", pairs, want)
+	}
+	var buf bytes.Buffer
+	if err := WritePairs(&buf, pairs); err != nil {
+		t.Fatal(err)
+	}
+	again, err := ReadPairs(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, pairs) {
+		t.Errorf("round trip = %+v, want %+v", again, pairs)
+	}
+
+	for _, bad := range []string{
+		"margules a b 1 2 3 4",
+		"NRTL a b 1 2 3 4",
+		"Wilson a a 1 2 3 4",
+		"Wilson a b 1 2 3",
+	} {
+		if _, err := ReadPairs(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func TestNewFromPairs(t *testing.T) {
+	pairs := []Pair{{Model: "Wilson", I: "propanol", J: "water",
+		IJ: propanolWater.LnLambda[0][1], JI: propanolWater.LnLambda[1][0]}}
+	// the order of names decides the matrix layout
+	m, err := NewWilson([]string{"water", "propanol"}, pairs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lnGamma := make([]float64, 2)
+	m.LnGamma(333.15, []float64{0.7, 0.3}, lnGamma)
+	if math.Abs(lnGamma[1]-0.7535251508338046) > 1e-12 {
+		t.Errorf("ln γ(propanol) = %g", lnGamma[1])
+	}
+	if _, err := NewWilson([]string{"water", "propanol"}, append(pairs, pairs[0])); err == nil {
+		t.Error("duplicate pair accepted")
+	}
+}
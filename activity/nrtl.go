@@ -0,0 +1,78 @@
+package activity
+
+import "math"
+
+// NRTL is the non-random two-liquid model with
+//
+//	τ_ij = Tau[i][j](T),  G_ij = exp(-α_ij τ_ij)
+//
+// The classical form τ_ij = (g_ij - g_jj)/RT corresponds to B = (g_ij - g_jj)/R.
+// Alpha holds the symmetric non-randomness parameters α_ij, typically 0.2
+// to 0.47.
+type NRTL struct {
+	Tau   [][]Coeff
+	Alpha [][]float64
+}
+
+// terms returns τ, G, D_i = Σ_k x_k G_ki and S_i = Σ_j x_j τ_ji G_ji, and
+// the temperature derivatives of τ and G
+func (m NRTL) terms(T float64, x []float64) (tau, g, dtau, dg [][]float64, d, s []float64) {
+	n := len(x)
+	tau, dtau = matrix(m.Tau, T, n)
+	g, dg = square(n), square(n)
+	for i := range n {
+		for j := range n {
+			a := 0.0
+			if m.Alpha != nil {
+				a = m.Alpha[i][j]
+			}
+			g[i][j] = math.Exp(-a * tau[i][j])
+			dg[i][j] = -a * dtau[i][j] * g[i][j]
+		}
+	}
+	d, s = make([]float64, n), make([]float64, n)
+	for i := range n {
+		for j := range n {
+			d[i] += x[j] * g[j][i]
+			s[i] += x[j] * tau[j][i] * g[j][i]
+		}
+	}
+	return tau, g, dtau, dg, d, s
+}
+
+func (m NRTL) LnGamma(T float64, x, lnGamma []float64) {
+	tau, g, _, _, d, s := m.terms(T, x)
+	for i := range x {
+		v := s[i] / d[i]
+		for j := range x {
+			v += x[j] * g[i][j] / d[j] * (tau[i][j] - s[j]/d[j])
+		}
+		lnGamma[i] = v
+	}
+}
+
+func (m NRTL) GE(T float64, x []float64) float64 {
+	_, _, _, _, d, s := m.terms(T, x)
+	ge := 0.0
+	for i := range x {
+		ge += x[i] * s[i] / d[i]
+	}
+	return ge
+}
+
+func (m NRTL) HE(T float64, x []float64) float64 {
+	tau, g, dtau, dg, d, s := m.terms(T, x)
+	// hE/R = -T² d(gE/RT)/dT
+	he := 0.0
+	for i := range x {
+		dd, ds := 0.0, 0.0
+		for j := range x {
+			dd += x[j] * dg[j][i]
+			ds += x[j] * (dtau[j][i]*g[j][i] + tau[j][i]*dg[j][i])
+		}
+		he -= x[i] * (ds*d[i] - s[i]*dd) / (d[i] * d[i])
+	}
+	return T * T * he
+}
+
+func (NRTL) Name() string { return "NRTL" }
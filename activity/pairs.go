@@ -0,0 +1,189 @@
+package activity
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Pair holds the binary parameters of one model for components I and J
+type Pair struct {
+	Model string  //"Wilson", "NRTL" or "UNIQUAC"
+	I, J  string  //component names
+	IJ    Coeff   //parameter (i, j): ln Λ_ij, τ_ij or ln τ_ij
+	JI    Coeff   //parameter (j, i)
+	Alpha float64 //NRTL non-randomness α_ij = α_ji, 0 for the other models
+}
+
+// ReadPairs parses a binary parameter file. Each non-blank line that does
+// not start with '#' is one pair:
+//
+//	model  i  j  A_ij B_ij C_ij D_ij  A_ji B_ji C_ji D_ji  [alpha]
+//
+// where the coefficients are those of Coeff, alpha is required for NRTL
+// and not allowed otherwise, and component names contain no whitespace.
+// Trailing C and D columns may be omitted from both halves together, i.e.
+// a line may give 4 or 8 coefficients.
+func ReadPairs(r io.Reader) ([]Pair, error) {
+	var pairs []Pair
+	var errs []error
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		p, err := parsePair(strings.Fields(text))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		pairs = append(pairs, p)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+func parsePair(f []string) (Pair, error) {
+	if len(f) < 3 {
+		return Pair{}, fmt.Errorf("want model and two component names, got %q", strings.Join(f, " "))
+	}
+	p := Pair{Model: canonicalModel(f[0]), I: f[1], J: f[2]}
+	if p.Model == "" {
+		return Pair{}, fmt.Errorf("unknown model %q", f[0])
+	}
+	if p.I == p.J {
+		return Pair{}, fmt.Errorf("pair of %s with itself", p.I)
+	}
+
+	nums := make([]float64, len(f)-3)
+	for i, s := range f[3:] {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return Pair{}, fmt.Errorf("column %d: %w", i+4, err)
+		}
+		nums[i] = v
+	}
+	if p.Model == "NRTL" {
+		if len(nums) != 5 && len(nums) != 9 {
+			return Pair{}, fmt.Errorf("NRTL wants 4 or 8 coefficients and alpha, got %d numbers", len(nums))
+		}
+		p.Alpha, nums = nums[len(nums)-1], nums[:len(nums)-1]
+	}
+	switch len(nums) {
+	case 4:
+		p.IJ = Coeff{A: nums[0], B: nums[1]}
+		p.JI = Coeff{A: nums[2], B: nums[3]}
+	case 8:
+		p.IJ = Coeff{A: nums[0], B: nums[1], C: nums[2], D: nums[3]}
+		p.JI = Coeff{A: nums[4], B: nums[5], C: nums[6], D: nums[7]}
+	default:
+		return Pair{}, fmt.Errorf("%s wants 4 or 8 coefficients, got %d", p.Model, len(nums))
+	}
+	return p, nil
+}
+
+// WritePairs writes pairs in the format read by ReadPairs
+func WritePairs(w io.Writer, pairs []Pair) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# model i j A_ij B_ij C_ij D_ij A_ji B_ji C_ji D_ji [alpha]")
+	for _, p := range pairs {
+		fmt.Fprintf(bw, "%s %s %s %s %s", p.Model, p.I, p.J, formatCoeff(p.IJ), formatCoeff(p.JI))
+		if canonicalModel(p.Model) == "NRTL" {
+			fmt.Fprintf(bw, " %s", strconv.FormatFloat(p.Alpha, 'g', -1, 64))
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
+
+func formatCoeff(c Coeff) string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	return f(c.A) + " " + f(c.B) + " " + f(c.C) + " " + f(c.D)
+}
+
+func canonicalModel(s string) string {
+	for _, m := range []string{"Wilson", "NRTL", "UNIQUAC"} {
+		if strings.EqualFold(s, m) {
+			return m
+		}
+	}
+	return ""
+}
+
+// binary fills the n×n parameter matrix of model for the named components
+// from pairs, reporting alpha for NRTL. Pairs naming other components are
+// ignored; missing pairs are left at zero, the ideal-solution value.
+func binary(model string, names []string, pairs []Pair) (c [][]Coeff, alpha [][]float64, err error) {
+	index := make(map[string]int, len(names))
+	for i, n := range names {
+		if _, dup := index[n]; dup {
+			return nil, nil, fmt.Errorf("duplicate component %q", n)
+		}
+		index[n] = i
+	}
+
+	n := len(names)
+	c = make([][]Coeff, n)
+	alpha = square(n)
+	for i := range c {
+		c[i] = make([]Coeff, n)
+	}
+	seen := make(map[[2]int]bool)
+	for _, p := range pairs {
+		if canonicalModel(p.Model) != model {
+			continue
+		}
+		i, iok := index[p.I]
+		j, jok := index[p.J]
+		if !iok || !jok {
+			continue
+		}
+		key := [2]int{min(i, j), max(i, j)}
+		if seen[key] {
+			return nil, nil, fmt.Errorf("%s pair %s-%s given twice", model, p.I, p.J)
+		}
+		seen[key] = true
+		c[i][j], c[j][i] = p.IJ, p.JI
+		alpha[i][j], alpha[j][i] = p.Alpha, p.Alpha
+	}
+	return c, alpha, nil
+}
+
+// NewWilson builds a Wilson model for the named components from the
+// "Wilson" entries of pairs. Missing pairs are ideal (Λ_ij = 1).
+func NewWilson(names []string, pairs []Pair) (Wilson, error) {
+	c, _, err := binary("Wilson", names, pairs)
+	return Wilson{LnLambda: c}, err
+}
+
+// NewNRTL builds an NRTL model for the named components from the "NRTL"
+// entries of pairs. Missing pairs are ideal (τ_ij = 0).
+func NewNRTL(names []string, pairs []Pair) (NRTL, error) {
+	c, alpha, err := binary("NRTL", names, pairs)
+	return NRTL{Tau: c, Alpha: alpha}, err
+}
+
+// NewUNIQUAC builds a UNIQUAC model for the named components, with
+// volume and surface parameters r and q, from the "UNIQUAC" entries of
+// pairs. Missing pairs have no residual contribution (τ_ij = 1).
+func NewUNIQUAC(names []string, r, q []float64, pairs []Pair) (UNIQUAC, error) {
+	if len(r) != len(names) || len(q) != len(names) {
+		return UNIQUAC{}, fmt.Errorf("UNIQUAC needs r and q for each of %d components, got %d and %d", len(names), len(r), len(q))
+	}
+	for i := range names {
+		if !(r[i] > 0) || !(q[i] > 0) {
+			return UNIQUAC{}, fmt.Errorf("UNIQUAC r and q of %s must be > 0 (got %g, %g)", names[i], r[i], q[i])
+		}
+	}
+	c, _, err := binary("UNIQUAC", names, pairs)
+	return UNIQUAC{R: append([]float64(nil), r...), Q: append([]float64(nil), q...), LnTau: c}, err
+}
@@ -0,0 +1,351 @@
+package activity
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+//go:embed unifac.txt
+var unifacData string
+
+// Subgroup is a UNIFAC functional subgroup
+type Subgroup struct {
+	ID   int
+	Name string
+	Main int     //main group, which sets the interaction parameters
+	R    float64 //volume parameter
+	Q    float64 //surface parameter
+}
+
+// Interaction holds the parameters between main groups m and n in
+//
+//	Ψ_mn = exp(-(a_mn + b_mn T + c_mn T²)/T)
+//
+// Original UNIFAC uses a_mn only.
+type Interaction struct {
+	A, B, C float64
+}
+
+// UNIFACTable holds UNIFAC subgroup and main-group interaction parameters
+type UNIFACTable struct {
+	Subgroups    map[string]Subgroup    //keyed by subgroup name
+	Interactions map[[2]int]Interaction //keyed by main groups (m, n)
+}
+
+// Groups gives the number of each UNIFAC subgroup, by name, in a component
+type Groups map[string]int
+
+// DefaultUNIFACTable returns a fresh copy of the embedded original UNIFAC
+// parameters (Hansen et al., 1991). Only the alkane, alkene, aromatic,
+// alcohol, methanol, water and ketone main groups are included; add
+// entries to the returned table, or read a fuller one with
+// ReadUNIFACTable, for other systems.
+func DefaultUNIFACTable() *UNIFACTable {
+	t, err := ReadUNIFACTable(strings.NewReader(unifacData))
+	if err != nil {
+		panic("activity: embedded UNIFAC table: " + err.Error())
+	}
+	return t
+}
+
+// ReadUNIFACTable parses a UNIFAC parameter file. Lines starting with '#'
+// are comments. A "[subgroups]" section lists
+//
+//	id  name  main  R  Q
+//
+// and an "[interactions]" section lists
+//
+//	m  n  a_mn  b_mn  c_mn
+//
+// with b_mn and c_mn optional.
+func ReadUNIFACTable(r io.Reader) (*UNIFACTable, error) {
+	t := &UNIFACTable{Subgroups: map[string]Subgroup{}, Interactions: map[[2]int]Interaction{}}
+	var errs []error
+	section := ""
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if strings.HasPrefix(text, "[") {
+			section = strings.ToLower(strings.Trim(text, "[]"))
+			if section != "subgroups" && section != "interactions" {
+				errs = append(errs, fmt.Errorf("line %d: unknown section %q", line, text))
+			}
+			continue
+		}
+		if err := t.parseLine(section, strings.Fields(text)); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *UNIFACTable) parseLine(section string, f []string) error {
+	switch section {
+	case "subgroups":
+		if len(f) != 5 {
+			return fmt.Errorf("subgroup wants id, name, main, R and Q, got %d fields", len(f))
+		}
+		id, err1 := strconv.Atoi(f[0])
+		main, err2 := strconv.Atoi(f[2])
+		r, err3 := strconv.ParseFloat(f[3], 64)
+		q, err4 := strconv.ParseFloat(f[4], 64)
+		if err := errors.Join(err1, err2, err3, err4); err != nil {
+			return err
+		}
+		if _, dup := t.Subgroups[f[1]]; dup {
+			return fmt.Errorf("subgroup %s given twice", f[1])
+		}
+		t.Subgroups[f[1]] = Subgroup{ID: id, Name: f[1], Main: main, R: r, Q: q}
+	case "interactions":
+		if len(f) < 3 || len(f) > 5 {
+			return fmt.Errorf("interaction wants m, n and 1 to 3 coefficients, got %d fields", len(f))
+		}
+		m, err1 := strconv.Atoi(f[0])
+		n, err2 := strconv.Atoi(f[1])
+		if err := errors.Join(err1, err2); err != nil {
+			return err
+		}
+		var c [3]float64
+		for i, s := range f[2:] {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return err
+			}
+			c[i] = v
+		}
+		t.Interactions[[2]int{m, n}] = Interaction{A: c[0], B: c[1], C: c[2]}
+	default:
+		return errors.New("data outside a [subgroups] or [interactions] section")
+	}
+	return nil
+}
+
+// UNIFAC is the UNIFAC group-contribution model: the UNIQUAC
+// combinatorial part from component r_i = Σ ν_ki R_k and q_i = Σ ν_ki Q_k,
+// and a residual part from group interactions. Build it with NewUNIFAC.
+type UNIFAC struct {
+	comb     UNIQUAC     //combinatorial part, no residual
+	nu       [][]float64 //nu[i][k] is the count of group k in component i
+	q        []float64   //Q_k of each distinct group
+	inter    [][]Interaction
+	residual bool //residual part only
+}
+
+// NewUNIFAC builds a UNIFAC model for components given by their subgroup
+// counts, using table (DefaultUNIFACTable when nil). It fails if a
+// subgroup is unknown or an interaction between two main groups present
+// in the mixture is missing.
+func NewUNIFAC(comps []Groups, table *UNIFACTable) (UNIFAC, error) {
+	if table == nil {
+		table = DefaultUNIFACTable()
+	}
+	if len(comps) == 0 {
+		return UNIFAC{}, errors.New("UNIFAC needs at least one component")
+	}
+
+	// distinct subgroups, in a stable order
+	var names []string
+	var errs []error
+	for i, c := range comps {
+		if len(c) == 0 {
+			errs = append(errs, fmt.Errorf("component %d has no groups", i))
+		}
+		for name, count := range c {
+			if _, ok := table.Subgroups[name]; !ok {
+				errs = append(errs, fmt.Errorf("component %d: unknown UNIFAC subgroup %q", i, name))
+			}
+			if count <= 0 {
+				errs = append(errs, fmt.Errorf("component %d: count of %s must be > 0 (got %d)", i, name, count))
+			}
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return UNIFAC{}, err
+	}
+	slices.SortFunc(names, func(a, b string) int { return table.Subgroups[a].ID - table.Subgroups[b].ID })
+
+	groups := make([]Subgroup, len(names))
+	for k, name := range names {
+		groups[k] = table.Subgroups[name]
+	}
+	m := UNIFAC{
+		comb:  UNIQUAC{R: make([]float64, len(comps)), Q: make([]float64, len(comps))},
+		nu:    make([][]float64, len(comps)),
+		q:     make([]float64, len(groups)),
+		inter: make([][]Interaction, len(groups)),
+	}
+	for k, g := range groups {
+		m.q[k] = g.Q
+		m.inter[k] = make([]Interaction, len(groups))
+		for l, h := range groups {
+			if g.Main == h.Main {
+				continue
+			}
+			p, ok := table.Interactions[[2]int{g.Main, h.Main}]
+			if !ok {
+				errs = append(errs, fmt.Errorf("no UNIFAC interaction between main groups %d (%s) and %d (%s)", g.Main, g.Name, h.Main, h.Name))
+			}
+			m.inter[k][l] = p
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return UNIFAC{}, err
+	}
+	for i, c := range comps {
+		m.nu[i] = make([]float64, len(groups))
+		for k, g := range groups {
+			nu := float64(c[g.Name])
+			m.nu[i][k] = nu
+			m.comb.R[i] += nu * g.R
+			m.comb.Q[i] += nu * g.Q
+		}
+	}
+	return m, nil
+}
+
+// Residual returns the model restricted to its residual part, as used by
+// mixing rules that account for the combinatorial part through b
+func (m UNIFAC) Residual() UNIFAC {
+	m.residual = true
+	return m
+}
+
+// R returns the UNIQUAC volume parameters r_i of the components
+func (m UNIFAC) R() []float64 { return slices.Clone(m.comb.R) }
+
+// Q returns the UNIQUAC surface parameters q_i of the components
+func (m UNIFAC) Q() []float64 { return slices.Clone(m.comb.Q) }
+
+// psi returns Ψ_kl and dΨ_kl/dT at T
+func (m UNIFAC) psi(T float64) (psi, dpsi [][]float64) {
+	n := len(m.q)
+	psi, dpsi = square(n), square(n)
+	for k := range n {
+		for l := range n {
+			p := m.inter[k][l]
+			psi[k][l] = math.Exp(-(p.A + p.B*T + p.C*T*T) / T)
+			dpsi[k][l] = psi[k][l] * (p.A/(T*T) - p.C)
+		}
+	}
+	return psi, dpsi
+}
+
+// groupTerms returns, for group amounts nk, S_k = Σ_m Θ_m Ψ_mk, its
+// temperature derivative and ln Γ_k
+func (m UNIFAC) groupTerms(nk []float64, psi, dpsi [][]float64) (s, ds, lnG []float64) {
+	n := len(nk)
+	theta := make([]float64, n)
+	sum := 0.0
+	for k := range n {
+		theta[k] = m.q[k] * nk[k]
+		sum += theta[k]
+	}
+	s, ds, lnG = make([]float64, n), make([]float64, n), make([]float64, n)
+	for k := range n {
+		theta[k] /= sum
+	}
+	for k := range n {
+		for l := range n {
+			s[k] += theta[l] * psi[l][k]
+			ds[k] += theta[l] * dpsi[l][k]
+		}
+	}
+	for k := range n {
+		v := 1 - math.Log(s[k])
+		for l := range n {
+			v -= theta[l] * psi[k][l] / s[l]
+		}
+		lnG[k] = m.q[k] * v
+	}
+	return s, ds, lnG
+}
+
+// mixtureGroups returns the group amounts Σ_i x_i ν_ki
+func (m UNIFAC) mixtureGroups(x []float64) []float64 {
+	nk := make([]float64, len(m.q))
+	for i := range x {
+		for k, nu := range m.nu[i] {
+			nk[k] += x[i] * nu
+		}
+	}
+	return nk
+}
+
+func (m UNIFAC) LnGamma(T float64, x, lnGamma []float64) {
+	if m.residual {
+		clear(lnGamma[:len(x)])
+	} else {
+		m.comb.LnGamma(T, x, lnGamma)
+	}
+	psi, dpsi := m.psi(T)
+	_, _, lnG := m.groupTerms(m.mixtureGroups(x), psi, dpsi)
+	for i := range x {
+		_, _, lnGi := m.groupTerms(m.nu[i], psi, dpsi)
+		for k, nu := range m.nu[i] {
+			if nu != 0 {
+				lnGamma[i] += nu * (lnG[k] - lnGi[k])
+			}
+		}
+	}
+}
+
+// residualTerms returns gE/RT and hE/R of the residual part: the group
+// form of the UNIQUAC residual term less that of the pure components
+func (m UNIFAC) residualTerms(T float64, x []float64) (ge, he float64) {
+	psi, dpsi := m.psi(T)
+	add := func(w float64, nk []float64) {
+		s, ds, _ := m.groupTerms(nk, psi, dpsi)
+		for k := range nk {
+			if nk[k] != 0 {
+				ge -= w * nk[k] * m.q[k] * math.Log(s[k])
+				he += w * nk[k] * m.q[k] * ds[k] / s[k]
+			}
+		}
+	}
+	add(1, m.mixtureGroups(x))
+	for i := range x {
+		if x[i] != 0 {
+			add(-x[i], m.nu[i])
+		}
+	}
+	return ge, T * T * he
+}
+
+func (m UNIFAC) GE(T float64, x []float64) float64 {
+	ge, _ := m.residualTerms(T, x)
+	if !m.residual {
+		ge += m.comb.GE(T, x)
+	}
+	return ge
+}
+
+func (m UNIFAC) HE(T float64, x []float64) float64 {
+	_, he := m.residualTerms(T, x)
+	return he
+}
+
+func (m UNIFAC) Name() string {
+	if m.residual {
+		return "UNIFAC (residual)"
+	}
+	return "UNIFAC"
+}
@@ -0,0 +1,108 @@
+package activity
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// acetone(1)/n-pentane(2) at 307 K and x1 = 0.047, the worked UNIFAC
+// example of Smith, Van Ness and Abbott (Appendix H): γ1 = 4.992 and
+// γ2 = 1.005
+func acetonePentane(t *testing.T) UNIFAC {
+	t.Helper()
+	u, err := NewUNIFAC([]Groups{{"CH3": 1, "CH3CO": 1}, {"CH3": 2, "CH2": 3}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestUNIFACAcetonePentane(t *testing.T) {
+	u := acetonePentane(t)
+	x := []float64{0.047, 0.953}
+	lnGamma := make([]float64, 2)
+	u.LnGamma(307, x, lnGamma)
+	for i, want := range []float64{4.992, 1.005} {
+		if g := math.Exp(lnGamma[i]); math.Abs(g-want) > 1e-3 {
+			t.Errorf("γ%d = %.4f, want %.3f", i+1, g, want)
+		}
+	}
+	if r, q := u.R(), u.Q(); math.Abs(r[0]-2.5735) > 1e-12 || math.Abs(q[1]-3.316) > 1e-12 {
+		t.Errorf("r = %v, q = %v", r, q)
+	}
+
+	// the residual part is the full model less the UNIQUAC combinatorial
+	// part, which is UNIQUAC with τ = 1
+	res := make([]float64, 2)
+	u.Residual().LnGamma(307, x, res)
+	comb := make([]float64, 2)
+	UNIQUAC{R: u.R(), Q: u.Q()}.LnGamma(307, x, comb)
+	for i := range x {
+		if d := lnGamma[i] - comb[i] - res[i]; math.Abs(d) > 1e-12 {
+			t.Errorf("component %d: full - combinatorial - residual = %g", i, d)
+		}
+	}
+}
+
+func TestUNIFACConsistency(t *testing.T) {
+	u := acetonePentane(t)
+	x := []float64{0.4, 0.6}
+	const T = 307.0
+	for _, m := range []UNIFAC{u, u.Residual()} {
+		lnGamma := make([]float64, 2)
+		m.LnGamma(T, x, lnGamma)
+		if sum := x[0]*lnGamma[0] + x[1]*lnGamma[1]; math.Abs(m.GE(T, x)-sum) > 1e-12 {
+			t.Errorf("%s: gE/RT = %g, Σ x ln γ = %g", m.Name(), m.GE(T, x), sum)
+		}
+		h := 1e-4 * T
+		num := -T * T * (m.GE(T+h, x) - m.GE(T-h, x)) / (2 * h)
+		if he := m.HE(T, x); math.Abs(he-num) > 1e-7*math.Abs(num) {
+			t.Errorf("%s: hE/R = %g, numeric %g", m.Name(), he, num)
+		}
+	}
+}
+
+func TestNewUNIFACErrors(t *testing.T) {
+	for name, groups := range map[string][]Groups{
+		"unknown subgroup": {{"CH4": 1}, {"H2O": 1}},
+		"no groups":        {{}, {"H2O": 1}},
+		"zero count":       {{"CH3": 0}, {"H2O": 1}},
+		"no components":    nil,
+	} {
+		if _, err := NewUNIFAC(groups, nil); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+	// a table without the interaction between the groups present
+	table := DefaultUNIFACTable()
+	delete(table.Interactions, [2]int{1, 7})
+	if _, err := NewUNIFAC([]Groups{{"CH3": 2}, {"H2O": 1}}, table); err == nil {
+		t.Error("missing interaction accepted")
+	}
+	// the copy is fresh, so the default table still has it
+	if _, err := NewUNIFAC([]Groups{{"CH3": 2}, {"H2O": 1}}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReadUNIFACTableErrors(t *testing.T) {
+	for name, in := range map[string]string{
+		"duplicate subgroup": "[subgroups]\n1 CH3 1 0.9011 0.848\n1 CH3 1 0.9011 0.848\n",
+		"unknown section":    "[subgroups]\n1 CH3 1 0.9011 0.848\n[groups]\n",
+		"outside a section":  "1 CH3 1 0.9011 0.848\n",
+		"short subgroup":     "[subgroups]\n1 CH3 1 0.9011\n",
+		"bad interaction":    "[interactions]\n1 2 x\n",
+	} {
+		if _, err := ReadUNIFACTable(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+	table, err := ReadUNIFACTable(strings.NewReader("[subgroups]\n1 CH3 1 0.9011 0.848\n[interactions]\n1 2 86.02 0.1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := table.Interactions[[2]int{1, 2}]; p != (Interaction{A: 86.02, B: 0.1}) {
+		t.Errorf("interaction %+v", p)
+	}
+}
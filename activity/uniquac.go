@@ -0,0 +1,118 @@
+package activity
+
+import "math"
+
+// uniquacZ is the lattice coordination number
+const uniquacZ = 10
+
+// UNIQUAC is the universal quasi-chemical model with pure-component
+// volume and surface parameters R and Q and
+//
+//	ln τ_ij = LnTau[i][j](T)
+//
+// The classical form τ_ij = exp(-Δu_ij/RT) corresponds to B = -Δu_ij/R.
+type UNIQUAC struct {
+	R, Q  []float64
+	LnTau [][]Coeff
+}
+
+// fractions returns the volume fractions divided by the mole fractions,
+// Φ_i/x_i, and the surface fractions θ_i. Both are well defined at x_i = 0.
+func (m UNIQUAC) fractions(x []float64) (phiX, theta []float64) {
+	n := len(x)
+	sr, sq := 0.0, 0.0
+	for i := range n {
+		sr += x[i] * m.R[i]
+		sq += x[i] * m.Q[i]
+	}
+	phiX, theta = make([]float64, n), make([]float64, n)
+	for i := range n {
+		phiX[i] = m.R[i] / sr
+		theta[i] = x[i] * m.Q[i] / sq
+	}
+	return phiX, theta
+}
+
+func (m UNIQUAC) tau(T float64, n int) (tau, dlnTau [][]float64) {
+	tau, dlnTau = matrix(m.LnTau, T, n)
+	for i := range tau {
+		for j := range tau[i] {
+			tau[i][j] = math.Exp(tau[i][j])
+		}
+	}
+	return tau, dlnTau
+}
+
+func (m UNIQUAC) LnGamma(T float64, x, lnGamma []float64) {
+	n := len(x)
+	phiX, theta := m.fractions(x)
+	tau, _ := m.tau(T, n)
+
+	sl := 0.0 // Σ x_j l_j
+	l := make([]float64, n)
+	for i := range n {
+		l[i] = uniquacZ/2*(m.R[i]-m.Q[i]) - (m.R[i] - 1)
+		sl += x[i] * l[i]
+	}
+	st := make([]float64, n) // st_j = Σ_k θ_k τ_kj
+	for j := range n {
+		for k := range n {
+			st[j] += theta[k] * tau[k][j]
+		}
+	}
+	sq := 0.0
+	for i := range n {
+		sq += x[i] * m.Q[i]
+	}
+
+	for i := range n {
+		// θ_i/Φ_i = (Q_i/Σ x Q)/(R_i/Σ x R)
+		comb := math.Log(phiX[i]) + uniquacZ/2*m.Q[i]*math.Log(m.Q[i]/sq/phiX[i]) + l[i] - phiX[i]*sl
+		res := 1 - math.Log(st[i])
+		for j := range n {
+			res -= theta[j] * tau[i][j] / st[j]
+		}
+		lnGamma[i] = comb + m.Q[i]*res
+	}
+}
+
+func (m UNIQUAC) GE(T float64, x []float64) float64 {
+	n := len(x)
+	phiX, theta := m.fractions(x)
+	tau, _ := m.tau(T, n)
+	sq := 0.0
+	for i := range n {
+		sq += x[i] * m.Q[i]
+	}
+	ge := 0.0
+	for i := range n {
+		if x[i] == 0 {
+			continue
+		}
+		st := 0.0
+		for j := range n {
+			st += theta[j] * tau[j][i]
+		}
+		ge += x[i] * (math.Log(phiX[i]) + uniquacZ/2*m.Q[i]*math.Log(m.Q[i]/sq/phiX[i]) - m.Q[i]*math.Log(st))
+	}
+	return ge
+}
+
+func (m UNIQUAC) HE(T float64, x []float64) float64 {
+	n := len(x)
+	_, theta := m.fractions(x)
+	tau, dlnTau := m.tau(T, n)
+	// only the residual part depends on T
+	he := 0.0
+	for i := range n {
+		st, dst := 0.0, 0.0
+		for j := range n {
+			st += theta[j] * tau[j][i]
+			dst += theta[j] * tau[j][i] * dlnTau[j][i]
+		}
+		he += m.Q[i] * x[i] * dst / st
+	}
+	return T * T * he
+}
+
+func (UNIQUAC) Name() string { return "UNIQUAC" }
@@ -0,0 +1,73 @@
+package activity
+
+import "math"
+
+// Wilson is the Wilson local-composition model with
+//
+//	ln Λ_ij = LnLambda[i][j](T)
+//
+// The classical form Λ_ij = (V_j/V_i) exp(-(λ_ij - λ_ii)/RT) corresponds
+// to A = ln(V_j/V_i) and B = -(λ_ij - λ_ii)/R. Wilson cannot predict
+// liquid–liquid splitting.
+type Wilson struct {
+	LnLambda [][]Coeff
+}
+
+func (m Wilson) lambda(T float64, n int) (l, dlnL [][]float64) {
+	l, dlnL = matrix(m.LnLambda, T, n)
+	for i := range l {
+		for j := range l[i] {
+			l[i][j] = math.Exp(l[i][j])
+		}
+	}
+	return l, dlnL
+}
+
+func (m Wilson) LnGamma(T float64, x, lnGamma []float64) {
+	n := len(x)
+	l, _ := m.lambda(T, n)
+	s := make([]float64, n) // s_i = Σ_j x_j Λ_ij
+	for i := range n {
+		for j := range n {
+			s[i] += x[j] * l[i][j]
+		}
+	}
+	for i := range n {
+		g := 1 - math.Log(s[i])
+		for k := range n {
+			g -= x[k] * l[k][i] / s[k]
+		}
+		lnGamma[i] = g
+	}
+}
+
+func (m Wilson) GE(T float64, x []float64) float64 {
+	n := len(x)
+	l, _ := m.lambda(T, n)
+	ge := 0.0
+	for i := range n {
+		s := 0.0
+		for j := range n {
+			s += x[j] * l[i][j]
+		}
+		ge -= x[i] * math.Log(s)
+	}
+	return ge
+}
+
+func (m Wilson) HE(T float64, x []float64) float64 {
+	n := len(x)
+	l, dlnL := m.lambda(T, n)
+	he := 0.0
+	for i := range n {
+		s, ds := 0.0, 0.0
+		for j := range n {
+			s += x[j] * l[i][j]
+			ds += x[j] * l[i][j] * dlnL[i][j]
+		}
+		he += x[i] * ds / s
+	}
+	return T * T * he
+}
+
+func (Wilson) Name() string { return "Wilson" }
@@ -0,0 +1,118 @@
+package cubiceos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// alphaFunc evaluates α and d ln α / d ln Tr at a reduced temperature
+type alphaFunc func(tr float64) (alpha, dlnAlpha float64)
+
+// alphaCompiler is implemented by EOS types that can precompute the
+// ω-dependent part of their alpha function
+type alphaCompiler interface {
+	compileAlpha(w float64) alphaFunc
+}
+
+// compileAlpha returns the alpha function of eos for acentric factor w.
+// Types without a compiled form fall back to Alpha with a numerical
+// derivative.
+func compileAlpha(eos EOSType, w float64) alphaFunc {
+	if c, ok := eos.(alphaCompiler); ok {
+		return c.compileAlpha(w)
+	}
+	return func(tr float64) (float64, float64) {
+		const h = 1e-6
+		alpha := eos.Alpha(tr, w)
+		up, down := eos.Alpha(tr*(1+h), w), eos.Alpha(tr*(1-h), w)
+		return alpha, (up - down) / (2 * h * alpha)
+	}
+}
+
+// soaveAlpha is the Soave form α = [1 + κ(1 - √Tr)]² shared by SRK and PR
+func soaveAlpha(kappa float64) alphaFunc {
+	return func(tr float64) (float64, float64) {
+		sq := math.Sqrt(tr)
+		c := 1 + kappa*(1-sq)
+		return c * c, -kappa * sq / c
+	}
+}
+
+// AlphaModel is a component-specific alpha function. Set on a Component it
+// replaces the generalized α(Tr, ω) of the EOS, e.g. with parameters
+// fitted to that compound's vapour pressure.
+type AlphaModel interface {
+	// Alpha returns α and d ln α / d ln Tr at a reduced temperature
+	Alpha(tr float64) (alpha, dlnAlpha float64)
+}
+
+// MathiasCopeman is the Mathias–Copeman alpha function used by PSRK,
+//
+//	α = [1 + C1(1 - √Tr) + C2(1 - √Tr)² + C3(1 - √Tr)³]²
+//
+// reducing to the Soave form with κ = C1 above Tc
+type MathiasCopeman struct {
+	C1, C2, C3 float64
+}
+
+func (m MathiasCopeman) Alpha(tr float64) (float64, float64) {
+	sq := math.Sqrt(tr)
+	s := 1 - sq
+	if tr >= 1 {
+		return soaveAlpha(m.C1)(tr)
+	}
+	c := 1 + s*(m.C1+s*(m.C2+s*m.C3))
+	return c * c, -sq * (m.C1 + s*(2*m.C2+3*m.C3*s)) / c
+}
+
+// Twu is the Twu (1991) alpha function used by VTPR,
+//
+//	α = Tr^(N(M-1)) exp[L(1 - Tr^(NM))]
+type Twu struct {
+	L, M, N float64
+}
+
+func (t Twu) Alpha(tr float64) (float64, float64) {
+	nm := t.N * t.M
+	trNM := math.Pow(tr, nm)
+	return math.Pow(tr, t.N*(t.M-1)) * math.Exp(t.L*(1-trNM)), t.N*(t.M-1) - t.L*nm*trNM
+}
+
+// WithAlpha is EOS with its generalized α(Tr, ω) replaced by Model, so a
+// compound-specific alpha function can be used wherever an EOSType is,
+// e.g. WithAlpha{EOS: PR{}, Model: MathiasCopeman{...}} in an EOSCfg. The
+// acentric factor is then ignored.
+type WithAlpha struct {
+	EOS   EOSType
+	Model AlphaModel
+}
+
+// validate checks that both the EOS and the alpha model are set, and
+// runs the wrapped EOS's own checks
+func (e WithAlpha) validate() error {
+	errs := []error{validateType(e.EOS)}
+	if e.Model == nil {
+		errs = append(errs, fmt.Errorf("%w: WithAlpha has no alpha Model", ErrInvalidInput))
+	}
+	return errors.Join(errs...)
+}
+
+func (e WithAlpha) Alpha(tr, _ float64) float64 {
+	alpha, _ := e.Model.Alpha(tr)
+	return alpha
+}
+
+func (e WithAlpha) compileAlpha(float64) alphaFunc { return e.Model.Alpha }
+
+func (e WithAlpha) Params() Params { return e.EOS.Params() }
+
+func (e WithAlpha) Name() string {
+	switch e.Model.(type) {
+	case MathiasCopeman:
+		return e.EOS.Name() + " (Mathias-Copeman)"
+	case Twu:
+		return e.EOS.Name() + " (Twu)"
+	}
+	return e.EOS.Name() + " (custom alpha)"
+}
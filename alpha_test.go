@@ -0,0 +1,26 @@
+package cubiceos
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithAlphaValidate(t *testing.T) {
+	for _, tc := range []struct {
+		eos  WithAlpha
+		want error
+	}{
+		{WithAlpha{Model: Twu{L: 0.1, M: 0.9, N: 2}}, ErrNoEOSType},
+		{WithAlpha{EOS: PR{}}, ErrInvalidInput},
+		{WithAlpha{EOS: CPA{A0: -1}, Model: Twu{L: 0.1, M: 0.9, N: 2}}, ErrInvalidInput},
+	} {
+		cfg := propaneCfg
+		cfg.Type = tc.eos
+		if _, err := Solve(cfg); !errors.Is(err, tc.want) {
+			t.Errorf("Solve(%+v): %v, want %v", tc.eos, err, tc.want)
+		}
+		if _, err := Compile(tc.eos, cfg.Tc, cfg.Pc, cfg.W, cfg.R); !errors.Is(err, tc.want) {
+			t.Errorf("Compile(%+v): %v, want %v", tc.eos, err, tc.want)
+		}
+	}
+}
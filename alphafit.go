@@ -0,0 +1,199 @@
+package cubiceos
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// SaturationPoint is one measured pure-component saturation point
+type SaturationPoint struct {
+	T  float64
+	P  float64 //vapour pressure, NaN if not measured
+	VL float64 //saturated liquid molar volume (1/molar density), NaN if not measured
+}
+
+// AlphaForm selects the alpha function FitAlpha regresses
+type AlphaForm int
+
+const (
+	// FitMathiasCopeman fits C1, C2 and C3 of MathiasCopeman
+	FitMathiasCopeman AlphaForm = iota
+	// FitTwu fits L, M and N of Twu
+	FitTwu
+)
+
+// AlphaFitOptions controls FitAlpha
+type AlphaFitOptions struct {
+	Form        AlphaForm
+	VolumeShift bool //also fit a Péneloux volume shift C to the liquid volumes
+}
+
+// AlphaResidual compares the fit with one data point
+type AlphaResidual struct {
+	SaturationPoint
+	PCalc  float64
+	VLCalc float64 //volume shift applied
+	DP     float64 //100 (PCalc - P)/P, NaN if P is not measured
+	DVL    float64 //100 (VLCalc - VL)/VL, NaN if VL is not measured
+}
+
+// AlphaFit is the result of FitAlpha
+type AlphaFit struct {
+	Alpha      AlphaModel //MathiasCopeman or Twu
+	C          float64    //volume shift, V = V_EOS - C; 0 unless VolumeShift
+	Params     []float64  //fitted alpha parameters, then C if VolumeShift
+	StdErr     []float64  //standard errors of Params, NaN if undefined
+	Pressure   Deviations //of DP, in %
+	Volume     Deviations //of DVL, in %
+	Residuals  []AlphaResidual
+	Iterations int
+	eos        EOSType
+}
+
+// EOS returns the fitted alpha function bound to the EOS it was fitted
+// for, for use in an EOSCfg or with Compile. The volume shift is not
+// carried: pure-component volumes from Solve or a Compiled are unshifted,
+// so subtract C from them. Only a Mixture applies C, through Component.
+func (f AlphaFit) EOS() EOSType { return WithAlpha{EOS: f.eos, Model: f.Alpha} }
+
+// Component returns a mixture Component carrying the fitted alpha
+// function and volume shift
+func (f AlphaFit) Component(name string, Tc, Pc, w float64) Component {
+	return Component{Name: name, Tc: Tc, Pc: Pc, W: w, Alpha: f.Alpha, C: f.C}
+}
+
+// WriteResiduals writes the residuals as CSV, for plotting
+func (f AlphaFit) WriteResiduals(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	g := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	cw.Write([]string{"T", "P", "VL", "P_calc", "VL_calc", "dP_pct", "dVL_pct"})
+	for _, r := range f.Residuals {
+		cw.Write([]string{g(r.T), g(r.P), g(r.VL), g(r.PCalc), g(r.VLCalc), g(r.DP), g(r.DVL)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// FitAlpha regresses the parameters of a compound-specific alpha function
+// for eos, and optionally a volume shift, against measured vapour
+// pressures and saturated liquid volumes by Levenberg–Marquardt. The
+// residuals are ln(P_calc/P) and, with VolumeShift, the relative liquid
+// volume deviations; the shift does not change the vapour pressure. Every
+// T must be below Tc.
+func FitAlpha(eos EOSType, Tc, Pc, w, R float64, data []SaturationPoint, opt AlphaFitOptions) (AlphaFit, error) {
+	err := errors.Join(validateType(eos), positive("Tc", Tc), positive("Pc", Pc), finite("W", w), positive("R", R))
+	if err != nil {
+		return AlphaFit{}, err
+	}
+	if len(data) == 0 {
+		return AlphaFit{}, fmt.Errorf("%w: no data to fit", ErrInvalidInput)
+	}
+	var errs []error
+	nP, nV := 0, 0
+	for i, d := range data {
+		if err := positive("T", d.T); err != nil {
+			errs = append(errs, fmt.Errorf("data[%d]: %w", i, err))
+		} else if d.T >= Tc {
+			errs = append(errs, fmt.Errorf("data[%d]: %w", i, &InvalidInputError{Field: "T", Value: d.T, Constraint: fmt.Sprintf("< Tc (%g)", Tc)}))
+		}
+		if !math.IsNaN(d.P) {
+			nP++
+			errs = append(errs, positive(fmt.Sprintf("data[%d].P", i), d.P))
+		}
+		if !math.IsNaN(d.VL) {
+			nV++
+			errs = append(errs, positive(fmt.Sprintf("data[%d].VL", i), d.VL))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return AlphaFit{}, err
+	}
+	if nP == 0 {
+		return AlphaFit{}, fmt.Errorf("%w: no data point has a measured vapour pressure", ErrInvalidInput)
+	}
+	if opt.VolumeShift && nV == 0 {
+		return AlphaFit{}, fmt.Errorf("%w: a volume shift needs saturated liquid volumes", ErrInvalidInput)
+	}
+
+	model := func(p []float64) AlphaModel {
+		if opt.Form == FitTwu {
+			return Twu{L: p[0], M: p[1], N: p[2]}
+		}
+		return MathiasCopeman{C1: p[0], C2: p[1], C3: p[2]}
+	}
+	saturate := func(p []float64) ([]Saturation, error) {
+		c := compile(eos, Tc, Pc, model(p).Alpha, R)
+		out := make([]Saturation, len(data))
+		for i, d := range data {
+			s, err := c.Saturation(d.T)
+			if err != nil {
+				return nil, fmt.Errorf("data[%d]: %w", i, err)
+			}
+			out[i] = s
+		}
+		return out, nil
+	}
+	residuals := func(p []float64) ([]float64, error) {
+		sat, err := saturate(p)
+		if err != nil {
+			return nil, err
+		}
+		var r []float64
+		for i, d := range data {
+			if !math.IsNaN(d.P) {
+				r = append(r, math.Log(sat[i].P/d.P))
+			}
+			if opt.VolumeShift && !math.IsNaN(d.VL) {
+				r = append(r, (sat[i].VL-p[3]-d.VL)/d.VL)
+			}
+		}
+		return r, nil
+	}
+
+	p0 := alphaGuess(eos, w, opt.Form)
+	if opt.VolumeShift {
+		p0 = append(p0, 0)
+	}
+	lm, err := levenbergMarquardt(residuals, p0)
+	if err != nil {
+		return AlphaFit{}, err
+	}
+	sat, err := saturate(lm.p)
+	if err != nil {
+		return AlphaFit{}, err
+	}
+
+	fit := AlphaFit{Alpha: model(lm.p), Params: lm.p, StdErr: lm.stdErr(), Iterations: lm.iterations, eos: eos}
+	if opt.VolumeShift {
+		fit.C = lm.p[3]
+	}
+	dp, dv := make([]float64, len(data)), make([]float64, len(data))
+	for i, d := range data {
+		vl := sat[i].VL - fit.C
+		dp[i] = 100 * (sat[i].P - d.P) / d.P
+		dv[i] = 100 * (vl - d.VL) / d.VL
+		fit.Residuals = append(fit.Residuals, AlphaResidual{SaturationPoint: d, PCalc: sat[i].P, VLCalc: vl, DP: dp[i], DVL: dv[i]})
+	}
+	fit.Pressure, fit.Volume = deviations(dp), deviations(dv)
+	return fit, nil
+}
+
+// alphaGuess returns starting parameters: the EOS's own Soave κ for
+// Mathias–Copeman, and Twu's generalized PR parameters interpolated in ω
+// for Twu
+func alphaGuess(eos EOSType, w float64, form AlphaForm) []float64 {
+	if form == FitTwu {
+		// Twu, Coon and Cunningham (1995), ω = 0 and ω = 1 sets
+		return []float64{
+			0.125283 + w*(0.511614-0.125283),
+			0.911807 + w*(0.784054-0.911807),
+			1.948150 + w*(2.812520-1.948150),
+		}
+	}
+	alpha, _ := compileAlpha(eos, w)(0.7)
+	return []float64{(math.Sqrt(alpha) - 1) / (1 - math.Sqrt(0.7)), 0, 0}
+}
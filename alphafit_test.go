@@ -0,0 +1,83 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFitAlphaRoundTrip(t *testing.T) {
+	const (
+		Tc, Pc, w = 369.8, 42.48, 0.152 //propane
+		shift     = 3.5                 //cm³/mol
+	)
+	for _, tc := range []struct {
+		name  string
+		eos   EOSType
+		alpha AlphaModel
+		opt   AlphaFitOptions
+	}{
+		{"Mathias-Copeman", PR{}, MathiasCopeman{C1: 0.62, C2: -0.15, C3: 0.4}, AlphaFitOptions{VolumeShift: true}},
+		{"Twu", PR{}, Twu{L: 0.2, M: 0.88, N: 2.1}, AlphaFitOptions{Form: FitTwu, VolumeShift: true}},
+		{"Mathias-Copeman SRK", SRK{}, MathiasCopeman{C1: 0.75, C2: 0.1, C3: -0.2}, AlphaFitOptions{}},
+	} {
+		// synthetic saturation data from the known alpha function and shift
+		c, err := Compile(WithAlpha{EOS: tc.eos, Model: tc.alpha}, Tc, Pc, w, barCm3R)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data []SaturationPoint
+		for tr := 0.5; tr < 0.96; tr += 0.05 {
+			s, err := c.Saturation(tr * Tc)
+			if err != nil {
+				t.Fatalf("%s: Tr = %g: %v", tc.name, tr, err)
+			}
+			data = append(data, SaturationPoint{T: tr * Tc, P: s.P, VL: s.VL - shift})
+		}
+		fit, err := FitAlpha(tc.eos, Tc, Pc, w, barCm3R, data, tc.opt)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		want := []float64{0, 0, 0}
+		switch a := tc.alpha.(type) {
+		case MathiasCopeman:
+			want = []float64{a.C1, a.C2, a.C3}
+		case Twu:
+			want = []float64{a.L, a.M, a.N}
+		}
+		if tc.opt.VolumeShift {
+			want = append(want, shift)
+		}
+		for i := range want {
+			if math.Abs(fit.Params[i]-want[i]) > 1e-4*math.Max(1, math.Abs(want[i])) {
+				t.Errorf("%s: parameters %v, want %v", tc.name, fit.Params, want)
+				break
+			}
+		}
+		if fit.Pressure.Max > 1e-5 {
+			t.Errorf("%s: largest pressure deviation %g%%", tc.name, fit.Pressure.Max)
+		}
+	}
+}
+
+func TestFitAlphaInvalid(t *testing.T) {
+	noP := []SaturationPoint{{T: 300, P: math.NaN(), VL: 90}}
+	noVL := []SaturationPoint{{T: 300, P: 10, VL: math.NaN()}}
+	for name, tc := range map[string]struct {
+		data []SaturationPoint
+		opt  AlphaFitOptions
+	}{
+		"no data":           {nil, AlphaFitOptions{}},
+		"no pressures":      {noP, AlphaFitOptions{}},
+		"no volumes":        {noVL, AlphaFitOptions{VolumeShift: true}},
+		"above Tc":          {[]SaturationPoint{{T: 400, P: 50, VL: math.NaN()}}, AlphaFitOptions{}},
+		"negative pressure": {[]SaturationPoint{{T: 300, P: -1, VL: math.NaN()}}, AlphaFitOptions{}},
+	} {
+		if _, err := FitAlpha(PR{}, 369.8, 42.48, 0.152, barCm3R, tc.data, tc.opt); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: %v, want ErrInvalidInput", name, err)
+		}
+	}
+	if _, err := FitAlpha(nil, 369.8, 42.48, 0.152, barCm3R, noVL, AlphaFitOptions{}); !errors.Is(err, ErrNoEOSType) {
+		t.Errorf("nil EOS: %v, want ErrNoEOSType", err)
+	}
+}
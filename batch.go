@@ -0,0 +1,117 @@
+package cubiceos
+
+import (
+	"context"
+	"iter"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// BatchResult is the outcome of solving one state point of a batch
+type BatchResult struct {
+	Index  int //position of the state point in the input
+	Result Result
+	Err    error
+}
+
+// SolveBatch solves every configuration in cfgs. See SolveSeq.
+func SolveBatch(ctx context.Context, cfgs []EOSCfg, workers int) <-chan BatchResult {
+	return SolveSeq(ctx, slices.Values(cfgs), workers)
+}
+
+// SolveSeq solves the state points yielded by cfgs on a pool of workers
+// (GOMAXPROCS when workers <= 0) and streams the results back in input
+// order. Only a bounded window of points is in flight at a time, so cfgs
+// may be arbitrarily long.
+//
+// The channel is closed once every point has been delivered, or early if
+// ctx is cancelled or its deadline passes; check ctx.Err() to tell the two
+// apart. The caller must either drain the channel or cancel ctx.
+func SolveSeq(ctx context.Context, cfgs iter.Seq[EOSCfg], workers int) <-chan BatchResult {
+	return pipeline(ctx, cfgs, workers, func(i int, cfg EOSCfg) BatchResult {
+		res, err := Solve(cfg)
+		return BatchResult{Index: i, Result: res, Err: err}
+	})
+}
+
+// pipeline applies fn to every element of seq on a bounded worker pool and
+// emits the outputs in input order
+func pipeline[In, Out any](ctx context.Context, seq iter.Seq[In], workers int, fn func(int, In) Out) <-chan Out {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	window := 4 * workers
+
+	type job struct {
+		i  int
+		in In
+	}
+	type done struct {
+		i   int
+		out Out
+	}
+
+	jobs := make(chan job)
+	// At most window jobs are in flight (see slots), so workers never
+	// block on this channel even after the collector has given up.
+	results := make(chan done, window)
+	slots := make(chan struct{}, window)
+	out := make(chan Out)
+
+	go func() {
+		defer close(jobs)
+		i := 0
+		for in := range seq {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- job{i, in}:
+			case <-ctx.Done():
+				return
+			}
+			i++
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for j := range jobs {
+				results <- done{j.i, fn(j.i, j.in)}
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	go func() {
+		defer close(out)
+		pending := make(map[int]Out, window)
+		next := 0
+		for d := range results {
+			pending[d.i] = d.out
+			for {
+				v, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+				<-slots
+				next++
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,119 @@
+package cubiceos
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSolveBatchOrder(t *testing.T) {
+	// far more points than the window of 4·workers, with every fifth one
+	// invalid; the errors are reported per point and don't stop the batch
+	const n = 200
+	cfgs := make([]EOSCfg, n)
+	for i := range cfgs {
+		cfgs[i] = propaneCfg
+		cfgs[i].P = 1 + float64(i)/2
+		if i%5 == 3 {
+			cfgs[i].Tc = -1
+		}
+	}
+	i := 0
+	for br := range SolveBatch(context.Background(), cfgs, 2) {
+		if br.Index != i {
+			t.Fatalf("result %d has index %d", i, br.Index)
+		}
+		want, wantErr := Solve(cfgs[i])
+		switch {
+		case i%5 == 3:
+			if !errors.Is(br.Err, ErrInvalidInput) {
+				t.Errorf("point %d: %v, want ErrInvalidInput", i, br.Err)
+			}
+		case br.Err != nil || wantErr != nil:
+			t.Errorf("point %d: %v", i, br.Err)
+		case br.Result != want:
+			t.Errorf("point %d: %+v, want %+v", i, br.Result, want)
+		}
+		i++
+	}
+	if i != n {
+		t.Errorf("%d results, want %d", i, n)
+	}
+}
+
+func TestPipelineReorders(t *testing.T) {
+	// later items finish first, so the collector must hold them back
+	const n = 50
+	seq := func(yield func(int) bool) {
+		for i := range n {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	next := 0
+	for v := range pipeline(context.Background(), seq, 3, func(i, in int) int {
+		time.Sleep(time.Duration(11-i%12) * 100 * time.Microsecond)
+		return in * in
+	}) {
+		if v != next*next {
+			t.Fatalf("output %d is %d, want %d", next, v, next*next)
+		}
+		next++
+	}
+	if next != n {
+		t.Errorf("%d outputs, want %d", next, n)
+	}
+}
+
+func TestSolveSeqCancel(t *testing.T) {
+	// an endless input: cancelling must close the channel and stop
+	// reading the input within a window of the last delivered point
+	const workers = 2
+	var yielded atomic.Int64
+	seq := func(yield func(EOSCfg) bool) {
+		for {
+			yielded.Add(1)
+			if !yield(propaneCfg) {
+				return
+			}
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := SolveSeq(ctx, seq, workers)
+	received := 0
+	for range out {
+		received++
+		if received == 100 {
+			cancel()
+			break
+		}
+	}
+	// a point already on its way out may still be delivered
+	closed := make(chan struct{})
+	go func() {
+		for range out {
+			received++
+		}
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+	if ctx.Err() == nil {
+		t.Error("ctx.Err() is nil after cancel")
+	}
+	// every point read past the last delivered one holds a window slot,
+	// bar the one the producer gave up on; it must also have stopped
+	time.Sleep(10 * time.Millisecond)
+	stopped := yielded.Load()
+	time.Sleep(10 * time.Millisecond)
+	if got := yielded.Load(); got != stopped || got > int64(received+4*workers+1) {
+		t.Errorf("input read %d points (still %d later) for %d delivered", stopped, got, received)
+	}
+}
@@ -0,0 +1,178 @@
+package cubiceos
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// BinaryPoint is one bubble point of a binary mixture
+type BinaryPoint struct {
+	T, P float64
+	X1   float64 //liquid mole fraction of the first component
+	Y1   float64 //vapour mole fraction of the first component
+}
+
+// BinaryDiagram is an isothermal P–x–y or isobaric T–x–y diagram of a
+// binary mixture. Points are bubble points at increasing X1; the bubble
+// curve is T or P against X1 and the dew curve the same against Y1.
+type BinaryDiagram struct {
+	Isothermal bool    //P–x–y at fixed T if true, T–x–y at fixed P otherwise
+	Fixed      float64 //the fixed T or P
+	Names      [2]string
+	Points     []BinaryPoint
+	Azeotropes []BinaryPoint //where X1 = Y1 inside the composition range
+	// Missing lists the liquid compositions with no bubble point, such as
+	// those beyond the mixture critical point of a P–x–y diagram above a
+	// component's Tc
+	Missing []float64
+}
+
+// Pxy returns the isothermal P–x–y diagram of a binary mixture at T, with
+// bubble points at n+1 evenly spaced liquid compositions
+func (m *Mixture) Pxy(T float64, n int) (BinaryDiagram, error) {
+	if err := positive("T", T); err != nil {
+		return BinaryDiagram{}, err
+	}
+	return m.binaryDiagram(T, n, true)
+}
+
+// Txy returns the isobaric T–x–y diagram of a binary mixture at P, with
+// bubble points at n+1 evenly spaced liquid compositions
+func (m *Mixture) Txy(P float64, n int) (BinaryDiagram, error) {
+	if err := positive("P", P); err != nil {
+		return BinaryDiagram{}, err
+	}
+	return m.binaryDiagram(P, n, false)
+}
+
+// binaryDiagram sweeps X1 from 0 to 1, starting each bubble point from
+// the last one converged
+func (m *Mixture) binaryDiagram(fixed float64, n int, isothermal bool) (BinaryDiagram, error) {
+	if m.Len() != 2 {
+		return BinaryDiagram{}, &InvalidInputError{Field: "components", Value: float64(m.Len()), Constraint: "2 for a binary diagram"}
+	}
+	if n < 2 {
+		return BinaryDiagram{}, &InvalidInputError{Field: "n", Value: float64(n), Constraint: ">= 2"}
+	}
+	d := BinaryDiagram{Isothermal: isothermal, Fixed: fixed, Names: [2]string{m.comps[0].Name, m.comps[1].Name}}
+
+	var last *VLEResult
+	solve := func(x1 float64) (VLEResult, error) {
+		x := []float64{x1, 1 - x1}
+		var T, P float64
+		var y []float64
+		if last != nil {
+			T, P, y = last.T, last.P, last.Y
+		} else if isothermal {
+			T, P, y = m.wilsonBubble(fixed, 0, x)
+		} else {
+			T, P, y = m.wilsonBubble(0, fixed, x)
+		}
+		if isothermal {
+			T = fixed
+		} else {
+			P = fixed
+		}
+		return m.bubble(T, P, x, y, isothermal)
+	}
+
+	var errs []error
+	var g []float64 //azeotrope function of each point
+	for k := range n + 1 {
+		x1 := float64(k) / float64(n)
+		res, err := solve(x1)
+		if err == nil {
+			var gk float64
+			if gk, err = m.azeotropeFunction(res); err == nil {
+				g = append(g, gk)
+			}
+		}
+		if err != nil {
+			d.Missing = append(d.Missing, x1)
+			errs = append(errs, fmt.Errorf("x1 = %g: %w", x1, err))
+			last = nil
+			continue
+		}
+		last = &res
+		d.Points = append(d.Points, BinaryPoint{T: res.T, P: res.P, X1: x1, Y1: res.Y[0]})
+	}
+	if len(d.Points) == 0 {
+		return BinaryDiagram{}, errors.Join(errs...)
+	}
+
+	// an azeotrope lies where the azeotrope function changes sign, the end
+	// intervals included
+	for k := 1; k < len(d.Points); k++ {
+		a, b := d.Points[k-1], d.Points[k]
+		if g[k-1]*g[k] >= 0 {
+			continue
+		}
+		last = &VLEResult{T: a.T, P: a.P, Y: []float64{a.Y1, 1 - a.Y1}}
+		var at VLEResult
+		x1, err := illinois(func(x1 float64) (float64, error) {
+			res, err := solve(x1)
+			if err != nil {
+				return 0, err
+			}
+			at = res
+			return m.azeotropeFunction(res)
+		}, a.X1, b.X1, g[k-1], g[k])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("azeotrope between x1 = %g and %g: %w", a.X1, b.X1, err))
+			continue
+		}
+		d.Azeotropes = append(d.Azeotropes, BinaryPoint{T: at.T, P: at.P, X1: x1, Y1: at.Y[0]})
+	}
+	// points beyond the critical point are expected to fail; the diagram
+	// is still returned with the error
+	return d, errors.Join(errs...)
+}
+
+// azeotropeFunction returns (Y1 - X1)/(X1 X2) at a binary bubble point.
+// It has the sign of Y1 - X1 inside the composition range and tends to
+// K1 - 1 and 1 - K2 at infinite dilution of either component, so a sign
+// change next to a pure end point is an azeotrope too.
+func (m *Mixture) azeotropeFunction(r VLEResult) (float64, error) {
+	x1 := r.X[0]
+	if x1 > 0 && x1 < 1 {
+		return (r.Y[0] - x1) / (x1 * (1 - x1)), nil
+	}
+	lnL, _, _, err := m.fugacity(r.T, r.P, r.X, PhaseLiquid)
+	if err != nil {
+		return 0, err
+	}
+	lnV, _, _, err := m.fugacity(r.T, r.P, r.Y, PhaseVapour)
+	if err != nil {
+		return 0, err
+	}
+	if x1 == 0 {
+		return math.Exp(lnL[0]-lnV[0]) - 1, nil
+	}
+	return 1 - math.Exp(lnL[1]-lnV[1]), nil
+}
+
+// XY returns the x–y curve of the diagram
+func (d BinaryDiagram) XY() (x, y []float64) {
+	x, y = make([]float64, len(d.Points)), make([]float64, len(d.Points))
+	for i, p := range d.Points {
+		x[i], y[i] = p.X1, p.Y1
+	}
+	return x, y
+}
+
+// WriteCSV writes the diagram points as CSV with a header row of
+// x1, y1, T and P, the mole fractions named after the first component
+func (d BinaryDiagram) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	cw.Write([]string{"x_" + d.Names[0], "y_" + d.Names[0], "T", "P"})
+	for _, p := range d.Points {
+		cw.Write([]string{f(p.X1), f(p.Y1), f(p.T), f(p.P)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,30 @@
+package cubiceos
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTxyAzeotropeEndInterval(t *testing.T) {
+	m, err := NewMixture(PR{}, []Component{
+		{Name: "benzene", Tc: 562.05, Pc: 48.95, W: 0.210},
+		{Name: "cyclohexane", Tc: 553.6, Pc: 40.73, W: 0.2096},
+	}, Classical{K: [][]float64{{0, 0.01}, {0.01, 0}}}, barCm3R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// with n = 2 the azeotrope near x1 = 0.58 lies in the last interval
+	for _, n := range []int{2, 20} {
+		d, err := m.Txy(1.01325, n)
+		if err != nil {
+			t.Fatalf("n = %d: %v", n, err)
+		}
+		if len(d.Azeotropes) != 1 {
+			t.Fatalf("n = %d: azeotropes %+v, want one", n, d.Azeotropes)
+		}
+		a := d.Azeotropes[0]
+		if math.Abs(a.X1-0.58198) > 1e-4 || math.Abs(a.Y1-a.X1) > 1e-8 || math.Abs(a.T-351.793) > 1e-3 {
+			t.Errorf("n = %d: azeotrope %+v, want x1 = 0.58198 at 351.793 K", n, a)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+)
+
+// Compiled is a cubic EOS bound to one compound. Everything that depends
+// only on Tc, Pc, ω and R is computed once by Compile, so the methods do
+// no redundant work; for the cubic equations they never allocate on
+// success. CPA solves its association state at every call and does
+// allocate. A Compiled is immutable and safe for concurrent use.
+type Compiled struct {
+	name  string
+	p     Params
+	tc    float64
+	pc    float64
+	r     float64
+	ac    float64 //a(Tc) = Ψ R²Tc²/Pc
+	b     float64
+	vc    float64 //critical volume predicted by the EOS
+	alpha alphaFunc
+	assoc *assocFluid //association term, CPA only
+}
+
+// Compile precomputes the constants of eos for a compound with critical
+// temperature Tc, critical pressure Pc and acentric factor w, using the
+// gas constant R
+func Compile(eos EOSType, Tc, Pc, w, R float64) (*Compiled, error) {
+	err := errors.Join(validateType(eos), positive("Tc", Tc), positive("Pc", Pc), finite("W", w), positive("R", R))
+	if err != nil {
+		return nil, err
+	}
+	c := compile(eos, Tc, Pc, compileAlpha(eos, w), R)
+	if cpa, ok := asCPA(eos); ok {
+		cpa.bind(c)
+	}
+	return c, nil
+}
+
+// compile builds a Compiled from checked inputs and an alpha function
+func compile(eos EOSType, Tc, Pc float64, alpha alphaFunc, R float64) *Compiled {
+	p := eos.Params()
+	return &Compiled{
+		name:  eos.Name(),
+		p:     p,
+		tc:    Tc,
+		pc:    Pc,
+		r:     R,
+		ac:    p.Psi * R * R * Tc * Tc / Pc,
+		b:     p.Omega * R * Tc / Pc,
+		vc:    criticalZ(p) * R * Tc / Pc,
+		alpha: alpha,
+	}
+}
+
+// Name returns the name of the underlying EOS
+func (c *Compiled) Name() string { return c.name }
+
+// A returns a(T)
+func (c *Compiled) A(T float64) float64 {
+	alpha, _ := c.alpha(T / c.tc)
+	return c.ac * alpha
+}
+
+// B returns b
+func (c *Compiled) B() float64 { return c.b }
+
+// Pressure evaluates the pressure-explicit EOS at T and molar volume V
+func (c *Compiled) Pressure(T, V float64) float64 {
+	if c.assoc != nil {
+		props, err := c.cpaResidual(T, V, 0)
+		if err != nil {
+			return math.NaN()
+		}
+		return props.z * c.r * T / V
+	}
+	return c.r*T/(V-c.b) - c.A(T)/((V+c.p.Epsilon*c.b)*(V+c.p.Sigma*c.b))
+}
+
+// Solve solves the EOS at T and P and interprets the roots as Solve does
+func (c *Compiled) Solve(T, P float64) (Result, error) {
+	if !isFinite(T) || !isFinite(P) || T <= 0 || P <= 0 {
+		return Result{}, errors.Join(positive("T", T), positive("P", P))
+	}
+	if c.assoc != nil {
+		return c.cpaSolve(T, P)
+	}
+	pt := c.point(T, P)
+	roots, err := SolveCubic(cubicCoefficients(c.p, pt.a, pt.b, T, P, c.r))
+	if err != nil {
+		return Result{}, err
+	}
+	return classify(pt, roots), nil
+}
+
+func (c *Compiled) point(T, P float64) point {
+	alpha, dlnA := c.alpha(T / c.tc)
+	return point{
+		name: c.name,
+		p:    c.p,
+		T:    T, P: P, R: c.r,
+		Tc: c.tc,
+		Vc: c.vc,
+		a:  c.ac * alpha, b: c.b,
+		dlnA: dlnA,
+	}
+}
+
+// residual returns Z, β = bP/RT, q = a/(bRT), I and d ln α/d ln Tr at T and V
+func (c *Compiled) residual(T, V float64) (z, beta, q, i, dlnAlpha float64) {
+	alpha, dlnAlpha := c.alpha(T / c.tc)
+	a := c.ac * alpha
+	rt := c.r * T
+	P := rt/(V-c.b) - a/((V+c.p.Epsilon*c.b)*(V+c.p.Sigma*c.b))
+	z = P * V / rt
+	beta = c.b * P / rt
+	q = a / (c.b * rt)
+	return z, beta, q, integralI(c.p, z, beta), dlnAlpha
+}
+
+// LnPhi returns ln φ at T and molar volume V
+func (c *Compiled) LnPhi(T, V float64) float64 {
+	if c.assoc != nil {
+		props, err := c.cpaResidual(T, V, 0)
+		return orNaN(props.lnPhi, err)
+	}
+	z, beta, q, i, _ := c.residual(T, V)
+	return z - 1 - math.Log(z-beta) - q*i
+}
+
+// ResidualEnthalpy returns H^R = RT[Z - 1 + (d ln α/d ln Tr - 1) q I] at T
+// and molar volume V, in the energy units implied by R
+func (c *Compiled) ResidualEnthalpy(T, V float64) float64 {
+	if c.assoc != nil {
+		props, err := c.cpaResidual(T, V, 0)
+		return orNaN(props.hr, err)
+	}
+	z, _, q, i, dlnAlpha := c.residual(T, V)
+	return c.r * T * (z - 1 + (dlnAlpha-1)*q*i)
+}
+
+// ResidualEntropy returns S^R = R[ln(Z - β) + (d ln α/d ln Tr) q I] at T and
+// molar volume V, in the units of R
+func (c *Compiled) ResidualEntropy(T, V float64) float64 {
+	if c.assoc != nil {
+		props, err := c.cpaResidual(T, V, 0)
+		return orNaN(props.sr, err)
+	}
+	z, beta, q, i, dlnAlpha := c.residual(T, V)
+	return c.r * (math.Log(z-beta) + dlnAlpha*q*i)
+}
@@ -0,0 +1,57 @@
+package cubiceos
+
+import "testing"
+
+// propane, in bar and cm³
+var propaneCfg = EOSCfg{Type: PR{}, T: 300, P: 10, Tc: 369.8, Pc: 42.48, W: 0.152, R: barCm3R}
+
+func TestCompiledAllocs(t *testing.T) {
+	cfg := propaneCfg
+	for _, eos := range []EOSType{VdW{}, RK{}, SRK{}, PR{}} {
+		c, err := Compile(eos, cfg.Tc, cfg.Pc, cfg.W, cfg.R)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := c.Solve(cfg.T, cfg.P)
+		if err != nil {
+			t.Fatalf("%s: %v", c.Name(), err)
+		}
+		v, ok := res.StableVolume()
+		if !ok {
+			t.Fatalf("%s: no stable volume", c.Name())
+		}
+		if _, err := c.Density(cfg.T, cfg.P, PhaseLiquid, 0); err != nil {
+			t.Fatalf("%s: %v", c.Name(), err)
+		}
+		for name, f := range map[string]func(){
+			"Solve":    func() { c.Solve(cfg.T, cfg.P) },
+			"Pressure": func() { c.Pressure(cfg.T, v) },
+			"LnPhi":    func() { c.LnPhi(cfg.T, v) },
+			"Density":  func() { c.Density(cfg.T, cfg.P, PhaseLiquid, 0) },
+		} {
+			if n := testing.AllocsPerRun(100, f); n != 0 {
+				t.Errorf("%s %s: %g allocations, want 0", c.Name(), name, n)
+			}
+		}
+	}
+}
+
+func BenchmarkSolve(b *testing.B) {
+	for b.Loop() {
+		if _, err := Solve(propaneCfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiledSolve(b *testing.B) {
+	c, err := Compile(propaneCfg.Type, propaneCfg.Tc, propaneCfg.Pc, propaneCfg.W, propaneCfg.R)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		if _, err := c.Solve(propaneCfg.T, propaneCfg.P); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
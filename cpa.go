@@ -0,0 +1,475 @@
+package cubiceos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Scheme is an association scheme in the terminology of Huang and
+// Radosz: the number of proton donor and acceptor sites on a molecule
+type Scheme int
+
+const (
+	SchemeNone Scheme = iota // non-associating
+	Scheme2B                 // one donor and one acceptor site, e.g. alcohols
+	Scheme4C                 // two donor and two acceptor sites, e.g. water, glycols
+)
+
+func (s Scheme) String() string {
+	switch s {
+	case SchemeNone:
+		return "none"
+	case Scheme2B:
+		return "2B"
+	case Scheme4C:
+		return "4C"
+	default:
+		return fmt.Sprintf("Scheme(%d)", int(s))
+	}
+}
+
+// sites returns the number of donor and of acceptor sites. Sites bond
+// only to sites of the other kind.
+func (s Scheme) sites() float64 {
+	switch s {
+	case Scheme2B:
+		return 1
+	case Scheme4C:
+		return 2
+	}
+	return 0
+}
+
+// Association holds the Wertheim association parameters of a compound
+type Association struct {
+	Scheme Scheme
+	EpsR   float64 //association energy ε/R (K)
+	Beta   float64 //association volume β (dimensionless)
+}
+
+// CPA is the Cubic-Plus-Association EOS of Kontogeorgis et al.: SRK plus
+// Wertheim's association term with the simplified radial distribution
+// function g = 1/(1 - 1.9η), η = b/4V,
+//
+//	P = RT/(V - b) - a/[V(V + b)] - (RT/2V)(1 + ρ ∂ln g/∂ρ) Σ_A (1 - X_A)
+//
+// where X_A is the fraction of sites A not bonded. The SRK part comes from
+// Tc, Pc and ω unless the fitted CPA parameters A0, B and C1 are given, in
+// the units of R; a(T) = A0[1 + C1(1 - √Tr)]² still uses Tc.
+//
+// The pressure equation is not cubic, so Solve, Compiled and Mixture find
+// its volume roots by scanning the pressure curve. CubicEOS returns the
+// roots of the SRK part only, and CriticalPoints does not support CPA. In
+// a Mixture the eos is CPA{} and each Component carries its own parameters
+// in Component.CPA; cross-association follows the CR-1 combining rule.
+type CPA struct {
+	Association
+	A0 float64 //fitted a0, 0 for Ψ R²Tc²/Pc
+	B  float64 //fitted b, 0 for Ω R Tc/Pc
+	C1 float64 //fitted Soave c1, 0 for SRK's κ(ω)
+}
+
+// associating is satisfied by CPA and *CPA
+type associating interface{ cpa() CPA }
+
+func (c CPA) cpa() CPA { return c }
+
+// asCPA reports whether eos is CPA, by value or by pointer, and returns it
+func asCPA(eos EOSType) (CPA, bool) {
+	if a, ok := eos.(associating); ok {
+		return a.cpa(), true
+	}
+	return CPA{}, false
+}
+
+func (c CPA) Alpha(tr, w float64) float64 {
+	if c.C1 == 0 {
+		return SRK{}.Alpha(tr, w)
+	}
+	alpha, _ := soaveAlpha(c.C1)(tr)
+	return alpha
+}
+
+func (c CPA) compileAlpha(w float64) alphaFunc {
+	if c.C1 == 0 {
+		return SRK{}.compileAlpha(w)
+	}
+	return soaveAlpha(c.C1)
+}
+
+func (CPA) Params() Params { return SRK{}.Params() }
+
+func (CPA) Name() string {
+	return "Cubic-Plus-Association"
+}
+
+// validate checks the association and fitted parameters
+func (c CPA) validate() error {
+	var errs []error
+	if c.Scheme < SchemeNone || c.Scheme > Scheme4C {
+		errs = append(errs, &InvalidInputError{Field: "Scheme", Value: float64(c.Scheme), Constraint: "a known association scheme"})
+	}
+	for _, f := range []struct {
+		name string
+		v    float64
+	}{{"EpsR", c.EpsR}, {"Beta", c.Beta}, {"A0", c.A0}, {"B", c.B}, {"C1", c.C1}} {
+		if !isFinite(f.v) || f.v < 0 {
+			errs = append(errs, &InvalidInputError{Field: f.name, Value: f.v, Constraint: ">= 0"})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// bind applies the fitted parameters and association term to a compiled
+// SRK
+func (c CPA) bind(comp *Compiled) {
+	if c.A0 != 0 {
+		comp.ac = c.A0
+	}
+	if c.B != 0 {
+		comp.b = c.B
+		comp.vc = criticalZ(comp.p) * c.B / comp.p.Omega
+	}
+	comp.assoc = &assocFluid{sites: []assocSite{c.site(comp.b)}}
+}
+
+func (c CPA) site(b float64) assocSite {
+	return assocSite{n: c.Scheme.sites(), eps: c.EpsR, beta: c.Beta, b: b}
+}
+
+// NewCPACfg creates a configuration for the Cubic-Plus-Association
+// equation of state
+func NewCPACfg(T, P, Tc, Pc, W, R float64, cpa CPA) EOSCfg {
+	return EOSCfg{
+		Type: cpa,
+		T:    T,
+		P:    P,
+		Tc:   Tc,
+		Pc:   Pc,
+		W:    W,
+		R:    R,
+	}
+}
+
+// assocSite holds the association parameters of one component
+type assocSite struct {
+	n         float64 //donor sites, equal to the acceptor sites
+	eps, beta float64 //ε/R and β
+	b         float64 //co-volume, for b_ij of the association strength
+}
+
+// assocFluid is the association term of a pure fluid or mixture
+type assocFluid struct {
+	sites []assocSite
+}
+
+// assocState is the association term at one T, V and composition
+type assocState struct {
+	x     []float64 //unbonded fractions: donors of each component, then acceptors
+	h     float64   //Σ x_i Σ_A (1 - X_iA)
+	f     float64   //A^assoc/(nRT)
+	z     float64   //Z^assoc
+	tdfdT float64   //T ∂f/∂T at constant V and composition
+	dlng  float64   //∂ln g/∂η
+	eta   float64   //b/4V
+}
+
+const (
+	assocMaxIter = 100
+	assocTol     = 1e-13
+)
+
+// delta returns Δ_ij/g and T ∂(Δ_ij/g)/∂T with the CR-1 combining rule
+func (f *assocFluid) delta(i, j int, T float64) (d, tdT float64) {
+	si, sj := f.sites[i], f.sites[j]
+	if si.beta == 0 || sj.beta == 0 {
+		return 0, 0
+	}
+	eps := 0.5 * (si.eps + sj.eps)
+	bb := 0.5 * (si.b + sj.b) * math.Sqrt(si.beta*sj.beta)
+	e := math.Exp(eps / T)
+	return (e - 1) * bb, -e * eps / T * bb
+}
+
+// state solves the mass-action equations for the unbonded site fractions
+// by Newton's method, starting from x0 when it is given, and evaluates the
+// association term. b is the mixture co-volume.
+func (f *assocFluid) state(T, V, b float64, x, x0 []float64) (assocState, error) {
+	n := len(f.sites)
+	s := assocState{eta: b / (4 * V)}
+	g := 1 / (1 - 1.9*s.eta)
+	s.dlng = 1.9 * g
+	rho := 1 / V
+
+	// m[i][j] is ρ x_j n_j Δ_ij, coupling the donors of i to the acceptors
+	// of j and the acceptors of i to the donors of j
+	m := make([][]float64, n)
+	for i := range n {
+		m[i] = make([]float64, n)
+		for j := range n {
+			d, _ := f.delta(i, j, T)
+			m[i][j] = rho * x[j] * f.sites[j].n * g * d
+		}
+	}
+	X := make([]float64, 2*n)
+	if len(x0) == 2*n {
+		copy(X, x0)
+	} else {
+		for i := range X {
+			X[i] = 1
+		}
+	}
+
+	converged := false
+	jac := make([][]float64, 2*n)
+	for k := range jac {
+		jac[k] = make([]float64, 2*n)
+	}
+	res := make([]float64, 2*n)
+	for range assocMaxIter {
+		for k := range 2 * n {
+			i, other := k%n, n*(1-k/n) // the partner block
+			sum := 0.0
+			for j := range n {
+				sum += m[i][j] * X[other+j]
+				jac[k][other+j] = -m[i][j]
+			}
+			res[k] = -(1/X[k] - 1 - sum)
+			jac[k][k] = -1 / (X[k] * X[k])
+		}
+		step, ok := solveLinear(jac, res)
+		if !ok {
+			return assocState{}, fmt.Errorf("%w: singular association Jacobian", ErrNoConvergence)
+		}
+		done := true
+		for k := range X {
+			next := X[k] + step[k]
+			switch {
+			case next <= 0:
+				next = 0.2 * X[k]
+			case next > 1:
+				next = 0.5 * (X[k] + 1)
+			}
+			if math.Abs(next-X[k]) > assocTol {
+				done = false
+			}
+			X[k] = next
+		}
+		if done {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return assocState{}, fmt.Errorf("%w: association site fractions at V = %g", ErrNoConvergence, V)
+	}
+
+	s.x = X
+	for i, si := range f.sites {
+		xd, xa := X[i], X[n+i]
+		s.h += x[i] * si.n * (2 - xd - xa)
+		s.f += x[i] * si.n * (math.Log(xd) - xd/2 + math.Log(xa) - xa/2 + 1)
+		for j, sj := range f.sites {
+			_, tdT := f.delta(i, j, T)
+			s.tdfdT -= 0.5 * rho * g * tdT * x[i] * x[j] * si.n * sj.n * (xd*X[n+j] + xa*X[j])
+		}
+	}
+	s.z = -0.5 * s.h * (1 + s.eta*s.dlng)
+	return s, nil
+}
+
+// lnPhi returns the association contribution to ln φ_k of a component
+// whose partial molar co-volume is bBar
+func (s assocState) lnPhi(k int, site assocSite, bBar, V float64) float64 {
+	n := len(s.x) / 2
+	out := -0.5 * s.h * s.dlng * bBar / (4 * V)
+	if site.n > 0 {
+		out += site.n * (math.Log(s.x[k]) + math.Log(s.x[n+k]))
+	}
+	return out
+}
+
+// cpaGridPoints is the number of volumes scanned for pressure roots
+const cpaGridPoints = 240
+
+// volumes returns the molar volumes, ascending, at which the CPA fluid of
+// composition x with SRK parameters a and b has pressure P. The pressure
+// curve is scanned on a geometric grid in V - b from 10⁻⁴b to beyond the
+// ideal-gas volume and every sign change is refined by regula falsi.
+// Roots alternate between mechanically stable (even index) and unstable.
+func (f *assocFluid) volumes(p Params, a, b, T, P, R float64, x []float64) ([]float64, error) {
+	rt := R * T
+	var warm []float64
+	excess := func(V float64) (float64, error) {
+		s, err := f.state(T, V, b, x, warm)
+		if err != nil {
+			return 0, err
+		}
+		warm = s.x
+		return rt/(V-b) - a/((V+p.Epsilon*b)*(V+p.Sigma*b)) + s.z*rt/V - P, nil
+	}
+
+	lo, hi := 1e-4*b, 10*(rt/P+b)
+	ratio := math.Pow(hi/lo, 1/float64(cpaGridPoints-1))
+	var out []float64
+	vPrev := b + lo
+	fPrev, err := excess(vPrev)
+	if err != nil {
+		return nil, err
+	}
+	for k := 1; k < cpaGridPoints; k++ {
+		v := b + lo*math.Pow(ratio, float64(k))
+		fv, err := excess(v)
+		if err != nil {
+			return nil, err
+		}
+		if fv == 0 || fPrev*fv < 0 {
+			saved := append([]float64(nil), warm...)
+			root, err := illinois(excess, vPrev, v, fPrev, fv)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, root)
+			warm = saved
+		}
+		vPrev, fPrev = v, fv
+	}
+	if len(out) == 0 {
+		return nil, ErrNoPhysicalRoot
+	}
+	return out, nil
+}
+
+// orNaN returns v, or NaN if err is set, for the methods of Compiled that
+// return a bare float64
+func orNaN(v float64, err error) float64 {
+	if err != nil {
+		return math.NaN()
+	}
+	return v
+}
+
+// cpaProperties holds Z and the residual properties at one volume
+type cpaProperties struct {
+	z, lnPhi, hr, sr float64
+}
+
+// cpaResidual evaluates a pure CPA fluid at T and V. Z is taken at P, or
+// at the EOS pressure at V when P is 0; at a root found for a given P
+// that keeps ln φ stationary in the small error of the volume, as for the
+// cubics.
+func (c *Compiled) cpaResidual(T, V, P float64) (cpaProperties, error) {
+	alpha, dlnAlpha := c.alpha(T / c.tc)
+	a := c.ac * alpha
+	rt := c.r * T
+	s, err := c.assoc.state(T, V, c.b, []float64{1}, nil)
+	if err != nil {
+		return cpaProperties{}, err
+	}
+	if P == 0 {
+		P = rt/(V-c.b) - a/((V+c.p.Epsilon*c.b)*(V+c.p.Sigma*c.b)) + s.z*rt/V
+	}
+	z := P * V / rt
+	q := a / (c.b * rt)
+	qi := q * integralI(c.p, V, c.b)
+	fr := -math.Log(1-c.b/V) - qi + s.f
+	tdfdT := -(dlnAlpha-1)*qi + s.tdfdT
+	return cpaProperties{
+		z:     z,
+		lnPhi: fr + z - 1 - math.Log(z),
+		hr:    rt * (z - 1 - tdfdT),
+		sr:    c.r * (math.Log(z) - tdfdT - fr),
+	}, nil
+}
+
+// cpaSolve implements Compiled.Solve for CPA. Of more than three roots the
+// smallest and the two largest are kept, so that the liquid, vapour and
+// the unstable root between them are reported.
+func (c *Compiled) cpaSolve(T, P float64) (Result, error) {
+	alpha, _ := c.alpha(T / c.tc)
+	a := c.ac * alpha
+	vols, err := c.assoc.volumes(c.p, a, c.b, T, P, c.r, []float64{1})
+	if err != nil {
+		return Result{}, err
+	}
+	if len(vols) > 3 {
+		vols = []float64{vols[0], vols[len(vols)-2], vols[len(vols)-1]}
+	}
+	res := Result{EOS: c.name, A: a, B: c.b, Stable: -1, Metastable: -1, NRoots: len(vols)}
+	for i, v := range vols {
+		props, err := c.cpaResidual(T, v, P)
+		if err != nil {
+			return Result{}, err
+		}
+		res.Volumes[i], res.Z[i] = v, props.z
+		res.LnPhi[i], res.HR[i], res.SR[i] = props.lnPhi, props.hr, props.sr
+	}
+	switch {
+	case len(vols) > 1:
+		res.Phase = PhaseTwoRoot
+		n := len(vols) - 1
+		res.Stable, res.Metastable = 0, n
+		if res.LnPhi[n] < res.LnPhi[0] {
+			res.Stable, res.Metastable = n, 0
+		}
+	case T >= c.tc:
+		res.Phase, res.Stable = PhaseSupercritical, 0
+	case vols[0] < c.vc:
+		res.Phase, res.Stable = PhaseLiquid, 0
+	default:
+		res.Phase, res.Stable = PhaseVapour, 0
+	}
+	return res, nil
+}
+
+// cpaDensity implements Density for CPA: the smallest root for a liquid,
+// the largest for a vapour
+func (c *Compiled) cpaDensity(T, P float64, phase Phase) (DensityResult, error) {
+	vols, err := c.assoc.volumes(c.p, c.A(T), c.b, T, P, c.r, []float64{1})
+	if err != nil {
+		return DensityResult{}, err
+	}
+	v := vols[len(vols)-1]
+	if phase == PhaseLiquid {
+		v = vols[0]
+	}
+	return DensityResult{V: v, Z: P * v / (c.r * T)}, nil
+}
+
+// cpaFugacity implements the ln φ evaluation of Mixture.fugacity for CPA,
+// returning ln φ, Z and the volume of the requested phase. The physical
+// part follows from the mixing rule's b̄_i and q̄_i at the CPA volume,
+//
+//	ln φ_i = (b̄_i/b)(Z_SRK - 1) - ln(1 - b/V) - q̄_i I + Σ_A ln X_iA
+//	         - (h/2)(∂ln g/∂η) b̄_i/4V - ln Z
+//
+// with Z_SRK the compressibility of the SRK part alone at V.
+func (m *Mixture) cpaFugacity(T, P float64, x []float64, b, q float64, bBar, qBar []float64, phase Phase) ([]float64, float64, float64, error) {
+	rt := m.r * T
+	a := q * b * rt
+	vols, err := m.assoc.volumes(m.p, a, b, T, P, m.r, x)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	v := vols[len(vols)-1]
+	if phase == PhaseLiquid {
+		v = vols[0]
+	}
+	s, err := m.assoc.state(T, v, b, x, nil)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	zSRK := v/(v-b) - a*v/(rt*(v+m.p.Epsilon*b)*(v+m.p.Sigma*b))
+	z := P * v / rt
+	i := integralI(m.p, v, b)
+	lnFree := -math.Log(1-b/v) - math.Log(z)
+	lnPhi := make([]float64, len(x))
+	zt := z
+	for k := range lnPhi {
+		shift := m.comps[k].C * P / rt
+		lnPhi[k] = bBar[k]/b*(zSRK-1) + lnFree - qBar[k]*i + s.lnPhi(k, m.assoc.sites[k], bBar[k], v) - shift
+		zt -= x[k] * shift
+	}
+	return lnPhi, zt, v, nil
+}
@@ -0,0 +1,56 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// water with the CPA parameters of Kontogeorgis et al., in bar and cm³
+var cpaWater = CPA{
+	Association: Association{Scheme: Scheme4C, EpsR: 2003.25, Beta: 0.0692},
+	A0:          1.2277e6,
+	B:           14.515,
+	C1:          0.67359,
+}
+
+const (
+	waterTc = 647.29
+	waterPc = 220.9
+	waterW  = 0.344
+	barCm3R = 83.14
+)
+
+func TestCPAWaterLiquidVolume(t *testing.T) {
+	for _, eos := range []EOSType{cpaWater, &cpaWater} {
+		res, err := Solve(EOSCfg{Type: eos, T: 298.15, P: 1.01325, Tc: waterTc, Pc: waterPc, W: waterW, R: barCm3R})
+		if err != nil {
+			t.Fatalf("%T: %v", eos, err)
+		}
+		v, ok := res.Liquid()
+		if !ok || math.Abs(v-17.93) > 0.01 {
+			t.Errorf("%T: liquid volume %g cm³/mol, want 17.93", eos, v)
+		}
+	}
+}
+
+func TestCPAWaterSaturation(t *testing.T) {
+	c, err := Compile(&cpaWater, waterTc, waterPc, waterW, barCm3R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sat, err := c.Saturation(373.15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(sat.P-1.002) > 0.001 {
+		t.Errorf("Psat(373.15 K) = %g bar, want 1.002", sat.P)
+	}
+}
+
+func TestCPAUnsupported(t *testing.T) {
+	_, err := SolveReduced(ReducedCfg{Type: &cpaWater, Tr: 0.5, Pr: 0.01, W: waterW})
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("SolveReduced: %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,407 @@
+package cubiceos
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// CriticalPoint is a critical point of a mixture of fixed composition
+type CriticalPoint struct {
+	Tc float64
+	Pc float64
+	Vc float64 //molar volume, volume translation applied
+	Zc float64
+}
+
+const (
+	critGrid    = 80   //volumes scanned between critVMin·b and critVMax·b
+	critVMin    = 1.05 //smallest v/b scanned
+	critVMax    = 20.0 //largest v/b scanned
+	critMaxIter = 100
+	critTol     = 1e-11
+)
+
+// criticalState holds the Heidemann–Khalil quantities of one composition
+type criticalState struct {
+	m  *Mixture
+	z  []float64
+	on []int //components with z_i > 0
+}
+
+// lnFugacity returns ln f_i - ln n_i (less the constant ln RT) of amounts
+// n in a total volume V, for i in s.on. Working at fixed T and V keeps
+// the derivatives free of any root selection:
+//
+//	ln f_i = ln n_i - ln(V - n b) + (b̄_i/b)(Z - 1) - q̄_i I
+func (s criticalState) lnFugacity(T, V float64, n []float64) ([]float64, error) {
+	k := len(n)
+	nt := 0.0
+	for _, v := range n {
+		nt += v
+	}
+	x := make([]float64, k)
+	for i, v := range n {
+		x[i] = v / nt
+	}
+	bBar, qBar := make([]float64, k), make([]float64, k)
+	b, q, err := s.m.mix(T, x, bBar, qBar)
+	if err != nil {
+		return nil, err
+	}
+	v := V / nt
+	if !(v > b) {
+		return nil, ErrNoPhysicalRoot
+	}
+	p := s.m.p
+	rt := s.m.r * T
+	eps, sig := (v+p.Epsilon*b)/b, (v+p.Sigma*b)/b
+	P := rt/(v-b) - q*rt/(b*eps*sig)
+	z := P * v / rt
+	var integral float64
+	if p.Sigma == p.Epsilon {
+		integral = 1 / eps
+	} else {
+		integral = math.Log(sig/eps) / (p.Sigma - p.Epsilon)
+	}
+	out := make([]float64, len(s.on))
+	for j, i := range s.on {
+		out[j] = -math.Log(V-nt*b) + bBar[i]/b*(z-1) - qBar[i]*integral
+	}
+	return out, nil
+}
+
+// stability returns the smallest eigenvalue of
+//
+//	Q_ij = √(z_i z_j) ∂ln f_i/∂n_j
+//
+// at T and total volume V for one mole of z, and its unit eigenvector
+func (s criticalState) stability(T, V float64) (float64, []float64, error) {
+	const h = 1e-6
+	k := len(s.on)
+	q := make([][]float64, k)
+	n := slices.Clone(s.z)
+	for j, c := range s.on {
+		n[c] = s.z[c] + h
+		up, err := s.lnFugacity(T, V, n)
+		if err != nil {
+			return 0, nil, err
+		}
+		n[c] = s.z[c] - h
+		down, err := s.lnFugacity(T, V, n)
+		if err != nil {
+			return 0, nil, err
+		}
+		n[c] = s.z[c]
+		for i := range k {
+			if q[i] == nil {
+				q[i] = make([]float64, k)
+			}
+			q[i][j] = math.Sqrt(s.z[s.on[i]]*s.z[c]) * (up[i] - down[i]) / (2 * h)
+		}
+	}
+	for i := range k {
+		// the ideal ln n_i term, exactly
+		q[i][i] += 1
+		for j := range i {
+			q[i][j] = 0.5 * (q[i][j] + q[j][i])
+			q[j][i] = q[i][j]
+		}
+	}
+	lambda, u := minEigen(q)
+	return lambda, u, nil
+}
+
+// cubic returns the Heidemann–Khalil cubic form
+//
+//	C = Σ Δn_i Δn_j Δn_k ∂²ln f_i/∂n_j∂n_k,  Δn_i = √z_i u_i
+//
+// as the second derivative of Σ Δn_i ln f_i along n + sΔn
+func (s criticalState) cubic(T, V float64, u []float64) (float64, error) {
+	const h = 1e-3
+	dn := make([]float64, len(u))
+	c := 0.0
+	for j, i := range s.on {
+		dn[j] = math.Sqrt(s.z[i]) * u[j]
+		c -= dn[j] * dn[j] * dn[j] / (s.z[i] * s.z[i])
+	}
+	g := func(step float64) (float64, error) {
+		n := slices.Clone(s.z)
+		for j, i := range s.on {
+			n[i] += step * dn[j]
+		}
+		f, err := s.lnFugacity(T, V, n)
+		if err != nil {
+			return 0, err
+		}
+		sum := 0.0
+		for j := range f {
+			sum += dn[j] * f[j]
+		}
+		return sum, nil
+	}
+	g0, err := g(0)
+	if err != nil {
+		return 0, err
+	}
+	gp, err := g(h)
+	if err != nil {
+		return 0, err
+	}
+	gm, err := g(-h)
+	if err != nil {
+		return 0, err
+	}
+	return c + (gp-2*g0+gm)/(h*h), nil
+}
+
+// spinodal returns the highest T at which the smallest eigenvalue of Q
+// vanishes at total volume V, its eigenvector and false if there is none
+// above tLow. It scans down from tHigh and refines the crossing by
+// regula falsi.
+func (s criticalState) spinodal(V, tLow, tHigh float64) (float64, []float64, bool, error) {
+	hi := tHigh
+	fHi, _, err := s.stability(hi, V)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	if fHi <= 0 {
+		return 0, nil, false, nil
+	}
+	lo := hi
+	var fLo float64
+	for {
+		lo *= 0.9
+		if lo < tLow {
+			return 0, nil, false, nil
+		}
+		if fLo, _, err = s.stability(lo, V); err != nil {
+			return 0, nil, false, err
+		}
+		if fLo < 0 {
+			break
+		}
+		hi, fHi = lo, fLo
+	}
+	T, err := illinois(func(T float64) (float64, error) {
+		f, _, err := s.stability(T, V)
+		return f, err
+	}, lo, hi, fLo, fHi)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	_, u, err := s.stability(T, V)
+	return T, u, err == nil, err
+}
+
+// illinois finds a root of f bracketed by [a, b], with f(a) = fa and
+// f(b) = fb of opposite signs, by the Illinois variant of regula falsi
+func illinois(f func(float64) (float64, error), a, b, fa, fb float64) (float64, error) {
+	side := 0
+	for range critMaxIter {
+		c := (a*fb - b*fa) / (fb - fa)
+		if math.Abs(b-a) <= critTol*math.Abs(c) {
+			return c, nil
+		}
+		fc, err := f(c)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case fc == 0:
+			return c, nil
+		case fc*fb > 0:
+			b, fb = c, fc
+			if side == -1 {
+				fa /= 2
+			}
+			side = -1
+		default:
+			a, fa = c, fc
+			if side == 1 {
+				fb /= 2
+			}
+			side = 1
+		}
+	}
+	return 0, fmt.Errorf("%w: regula falsi on [%g, %g]", ErrNoConvergence, a, b)
+}
+
+// CriticalPoints returns the critical points of composition z predicted
+// by the EOS, found with the Heidemann–Khalil criteria: the smallest
+// eigenvalue of the matrix of ∂ln f_i/∂n_j and the cubic form along its
+// eigenvector both vanish. Volumes from 1.05 b to 20 b are scanned, so
+// liquid–liquid critical points are found as well as the vapour–liquid
+// one; they are returned in order of increasing Vc, and only those with
+// Pc > 0. ErrNoCriticalPoint is returned if there are none.
+func (m *Mixture) CriticalPoints(z []float64) ([]CriticalPoint, error) {
+	if m.assoc != nil {
+		return nil, fmt.Errorf("%w: critical points of a CPA mixture", ErrUnsupported)
+	}
+	z, err := m.composition(z)
+	if err != nil {
+		return nil, err
+	}
+	s := criticalState{m: m, z: z}
+	tHigh, tLow, tMix := 0.0, math.Inf(1), 0.0
+	for i, c := range m.comps {
+		if z[i] > 0 {
+			s.on = append(s.on, i)
+			tHigh = math.Max(tHigh, 3*c.Tc)
+			tLow = math.Min(tLow, 0.1*c.Tc)
+			tMix += z[i] * c.Tc
+		}
+	}
+	// the volume grid is scaled by b at the pseudo-critical temperature
+	_, b, err := m.AB(tMix, z)
+	if err != nil {
+		return nil, err
+	}
+
+	type sample struct {
+		v, T, c float64
+		u       []float64
+	}
+	eval := func(v float64, ref []float64) (sample, bool, error) {
+		T, u, ok, err := s.spinodal(v, tLow, tHigh)
+		if err != nil || !ok {
+			return sample{}, false, err
+		}
+		// keep the eigenvector's sign continuous, since it sets C's sign
+		dot := 0.0
+		for i := range u {
+			dot += u[i] * ref[i]
+		}
+		if dot < 0 {
+			for i := range u {
+				u[i] = -u[i]
+			}
+		}
+		c, err := s.cubic(T, v, u)
+		return sample{v, T, c, u}, err == nil, err
+	}
+
+	var out []CriticalPoint
+	var prev *sample
+	ref := make([]float64, len(s.on))
+	ref[0] = 1
+	for k := range critGrid + 1 {
+		v := b * critVMin * math.Pow(critVMax/critVMin, float64(k)/critGrid)
+		cur, ok, err := eval(v, ref)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			prev = nil
+			continue
+		}
+		ref = cur.u
+		if prev != nil && prev.c*cur.c < 0 {
+			lo := *prev
+			vc, err := illinois(func(v float64) (float64, error) {
+				smp, ok, err := eval(v, lo.u)
+				if err == nil && !ok {
+					err = fmt.Errorf("%w: spinodal lost at V = %g", ErrNoConvergence, v)
+				}
+				return smp.c, err
+			}, prev.v, cur.v, prev.c, cur.c)
+			if err != nil {
+				return nil, err
+			}
+			smp, _, err := eval(vc, lo.u)
+			if err != nil {
+				return nil, err
+			}
+			// a sign change across a jump between spinodal branches is not
+			// a root
+			if math.Abs(smp.c) <= 1e-3*math.Max(math.Abs(prev.c), math.Abs(cur.c)) {
+				if cp := m.criticalPoint(smp.T, vc, z); cp.Pc > 0 {
+					out = append(out, cp)
+				}
+			}
+		}
+		prev = &cur
+	}
+	if len(out) == 0 {
+		return nil, ErrNoCriticalPoint
+	}
+	return out, nil
+}
+
+// criticalPoint evaluates P and applies the volume translation at a
+// converged critical T and EOS molar volume v
+func (m *Mixture) criticalPoint(T, v float64, z []float64) CriticalPoint {
+	n := len(z)
+	bm, q, _ := m.mix(T, z, make([]float64, n), make([]float64, n))
+	rt := m.r * T
+	P := rt/(v-bm) - q*bm*rt/((v+m.p.Epsilon*bm)*(v+m.p.Sigma*bm))
+	for i, c := range m.comps {
+		v -= z[i] * c.C
+	}
+	return CriticalPoint{Tc: T, Pc: P, Vc: v, Zc: P * v / rt}
+}
+
+// minEigen returns the smallest eigenvalue of the symmetric matrix a and
+// its unit eigenvector, by cyclic Jacobi rotations
+func minEigen(a [][]float64) (float64, []float64) {
+	n := len(a)
+	a = cloneMatrix(a)
+	vec := make([][]float64, n)
+	for i := range vec {
+		vec[i] = make([]float64, n)
+		vec[i][i] = 1
+	}
+	for range 100 {
+		off := 0.0
+		for i := range n {
+			for j := range i {
+				off += a[i][j] * a[i][j]
+			}
+		}
+		if off < 1e-30 {
+			break
+		}
+		for p := range n {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := range n {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p], a[k][q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := range n {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k], a[q][k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				for k := range n {
+					vkp, vkq := vec[k][p], vec[k][q]
+					vec[k][p], vec[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+	best := 0
+	for i := range n {
+		if a[i][i] < a[best][best] {
+			best = i
+		}
+	}
+	u := make([]float64, n)
+	for k := range n {
+		u[k] = vec[k][best]
+	}
+	return a[best][best], u
+}
+
+func cloneMatrix(a [][]float64) [][]float64 {
+	out := make([][]float64, len(a))
+	for i := range a {
+		out[i] = slices.Clone(a[i])
+	}
+	return out
+}
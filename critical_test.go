@@ -0,0 +1,48 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCriticalPointsTernary(t *testing.T) {
+	m, z := ternaryPR(t)
+	cps, err := m.CriticalPoints(z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cps) != 1 {
+		t.Fatalf("got %d critical points, want 1: %+v", len(cps), cps)
+	}
+	cp := cps[0]
+	if math.Abs(cp.Tc-401.24) > 0.05 || math.Abs(cp.Pc-190.77) > 0.05 {
+		t.Errorf("critical point %g K, %g bar, want 401.24 K, 190.77 bar", cp.Tc, cp.Pc)
+	}
+	if math.Abs(cp.Zc-cp.Pc*cp.Vc/(barCm3R*cp.Tc)) > 1e-12 {
+		t.Errorf("Zc = %g inconsistent with Pc Vc/(R Tc)", cp.Zc)
+	}
+
+	// the Heidemann–Khalil point and the envelope crossing must agree
+	env, err := m.PhaseEnvelope(z, EnvelopeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(env.CriticalT-cp.Tc) > 0.1 || math.Abs(env.CriticalP-cp.Pc) > 0.1 {
+		t.Errorf("envelope critical point %g K, %g bar; Heidemann–Khalil %g K, %g bar",
+			env.CriticalT, env.CriticalP, cp.Tc, cp.Pc)
+	}
+}
+
+func TestCriticalPointsCPA(t *testing.T) {
+	m, err := NewMixture(CPA{}, []Component{
+		{Name: "water", Tc: waterTc, Pc: waterPc, W: waterW, CPA: cpaWater},
+		{Name: "methane", Tc: 190.6, Pc: 46.0, W: 0.012},
+	}, nil, barCm3R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.CriticalPoints([]float64{0.5, 0.5}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("CPA critical points: %v, want ErrUnsupported", err)
+	}
+}
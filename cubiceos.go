@@ -1,9 +1,5 @@
 package cubiceos
 
-import (
-	"errors"
-)
-
 // Params represents the substance agnostic variables in any
 // cubic equation of state
 type Params struct {
@@ -33,50 +29,33 @@ type EOSCfg struct {
 
 // CubicEOS solves the cubic equation and returns the volumes
 func CubicEOS(cfg EOSCfg) ([3]complex128, error) {
-	if cfg.T <= 0 {
-		return [3]complex128{}, errors.New("absolute temp cannot be less than 0")
-	}
-
-	if cfg.P <= 0 {
-		return [3]complex128{}, errors.New("pressure cannot be less than or equal to 0")
-	}
-
-	if cfg.Tc <= 0 {
-		return [3]complex128{}, errors.New("critical temp cannot be less than or equal to 0")
-	}
-
-	if cfg.Pc <= 0 {
-		return [3]complex128{}, errors.New("critical pressure cannot be less than or equal to 0")
+	if err := cfg.Validate(); err != nil {
+		return [3]complex128{}, err
 	}
 
-	if cfg.R <= 0 {
-		return [3]complex128{}, errors.New("universal gas constant cannot be less than or equal to 0")
-	}
-
-	//Reduced components
-	tr := cfg.T / cfg.Tc
-	//pr := cfg.P/cfg.Pc
-
-	//a(T)
-	a := cfg.Type.Params().Psi * cfg.Type.Alpha(tr, cfg.W) * cfg.R * cfg.R * cfg.Tc * cfg.Tc / cfg.Pc
-	//debug
-	// fmt.Printf("alpha: %+f\n", cfg.Type.Alpha(tr, cfg.W))//correct
-	// fmt.Printf("Psi: %+f\n", cfg.Type.Params().Psi) //correct
-
-	b := cfg.Type.Params().Omega * cfg.R * cfg.Tc / cfg.Pc
-	//fmt.Printf("Omega: %+f\n", cfg.Type.Params().Omega) //correct
+	a, b := cfg.ab()
+	return SolveCubic(cubicCoefficients(cfg.Type.Params(), a, b, cfg.T, cfg.P, cfg.R))
+}
 
+// cubicCoefficients returns the coefficients of the cubic in V
+func cubicCoefficients(p Params, a, b, T, P, R float64) (e, f, g, h float64) {
 	//eV^3 + fV^2 + gV + h = 0
 
-	x := cfg.Type.Params().Epsilon + cfg.Type.Params().Sigma
-	y := cfg.Type.Params().Epsilon * cfg.Type.Params().Sigma
-	v_ig := cfg.R * cfg.T / cfg.P
-
-	e := 1.0
-	f := b*(x-1) - v_ig
-	g := b*((y-x)*b-(x*v_ig)) + a/cfg.P
-	h := -y*b*b*(b+v_ig) - a*b/cfg.P
+	x := p.Epsilon + p.Sigma
+	y := p.Epsilon * p.Sigma
+	v_ig := R * T / P
 
-	return SolveCubic(e, f, g, h)
+	e = 1.0
+	f = b*(x-1) - v_ig
+	g = b*((y-x)*b-(x*v_ig)) + a/P
+	h = -y*b*b*(b+v_ig) - a*b/P
+	return e, f, g, h
+}
 
+// ab returns the EOS parameters a(T) and b
+func (cfg EOSCfg) ab() (a, b float64) {
+	p := cfg.Type.Params()
+	a = p.Psi * cfg.Type.Alpha(cfg.Tr(), cfg.W) * cfg.R * cfg.R * cfg.Tc * cfg.Tc / cfg.Pc
+	b = p.Omega * cfg.R * cfg.Tc / cfg.Pc
+	return a, b
 }
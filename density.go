@@ -0,0 +1,117 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+)
+
+// DensityResult is the outcome of a phase-targeted volume solve
+type DensityResult struct {
+	V          float64 //molar volume
+	Z          float64
+	Iterations int  //Newton iterations taken
+	FellBack   bool //Newton failed and the root was taken from SolveCubic
+}
+
+const (
+	densityMaxIter = 50
+	densityTol     = 1e-12
+	// densityMaxBisect bounds consecutive safeguard steps before Newton is
+	// abandoned; the requested branch probably does not exist at (T, P)
+	densityMaxBisect = 10
+)
+
+// Density finds the molar volume of the given phase (PhaseLiquid or
+// PhaseVapour) at T and P by safeguarded Newton iteration on the
+// pressure-explicit EOS, starting from v0. A non-positive v0 starts from
+// 1.1b for a liquid and RT/P + b for a vapour.
+//
+// If Newton does not converge on the requested branch the cubic is solved
+// analytically and the smallest (liquid) or largest (vapour) physical root
+// is returned with FellBack set. When only one physical root exists that
+// root is returned whatever the hint. CPA takes the smallest or largest
+// root of its pressure scan directly, ignoring v0.
+func (c *Compiled) Density(T, P float64, phase Phase, v0 float64) (DensityResult, error) {
+	if !isFinite(T) || !isFinite(P) || T <= 0 || P <= 0 {
+		return DensityResult{}, errors.Join(positive("T", T), positive("P", P))
+	}
+	if phase != PhaseLiquid && phase != PhaseVapour {
+		return DensityResult{}, &InvalidInputError{Field: "phase", Value: float64(phase), Constraint: "liquid or vapour"}
+	}
+	if c.assoc != nil {
+		return c.cpaDensity(T, P, phase)
+	}
+	return density(c.p, c.A(T), c.b, T, P, c.r, phase, v0)
+}
+
+// density implements Density for explicit a and b so that it can be
+// reused for mixtures
+func density(p Params, a, b, T, P, R float64, phase Phase, v0 float64) (DensityResult, error) {
+	rt := R * T
+	liquid := phase == PhaseLiquid
+
+	v := v0
+	if !(v > b) || !isFinite(v) {
+		if liquid {
+			v = 1.1 * b
+		} else {
+			v = rt/P + b
+		}
+	}
+
+	// Critical volume predicted by the EOS, Vc = Zc b/Ω. The liquid root
+	// lies below it and the vapour root above it, so converging on the
+	// wrong side means Newton jumped branches.
+	vc := criticalZ(p) * b / p.Omega
+
+	it, forced := 0, 0
+	for it < densityMaxIter && forced < densityMaxBisect {
+		it++
+		d := (v + p.Epsilon*b) * (v + p.Sigma*b)
+		f := rt/(v-b) - a/d - P
+		df := -rt/((v-b)*(v-b)) + a*(2*v+(p.Epsilon+p.Sigma)*b)/(d*d)
+
+		var next float64
+		if df < 0 {
+			next = v - f/df
+		}
+		// Outside the mechanically stable branch, or stepping past b:
+		// move towards the requested phase instead
+		if df >= 0 || !(next > b) || !isFinite(next) {
+			forced++
+			if liquid {
+				next = b + 0.5*(v-b)
+			} else {
+				next = 2 * v
+			}
+		} else {
+			forced = 0
+		}
+
+		if math.Abs(next-v) <= densityTol*v {
+			if df < 0 && liquid == (next < vc) {
+				return DensityResult{V: next, Z: P * next / rt, Iterations: it}, nil
+			}
+			break
+		}
+		v = next
+	}
+
+	roots, err := SolveCubic(cubicCoefficients(p, a, b, T, P, R))
+	if err != nil {
+		return DensityResult{}, err
+	}
+	best := math.NaN()
+	for _, r := range roots {
+		if math.Abs(imag(r)) > imagTol*math.Max(1, math.Abs(real(r))) || real(r) <= b {
+			continue
+		}
+		if x := real(r); math.IsNaN(best) || (liquid && x < best) || (!liquid && x > best) {
+			best = x
+		}
+	}
+	if math.IsNaN(best) {
+		return DensityResult{}, ErrNoPhysicalRoot
+	}
+	return DensityResult{V: best, Z: P * best / rt, Iterations: it, FellBack: true}, nil
+}
@@ -0,0 +1,56 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDensity(t *testing.T) {
+	c, err := Compile(PR{}, propaneCfg.Tc, propaneCfg.Pc, propaneCfg.W, barCm3R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name     string
+		T, P, v0 float64
+		phase    Phase
+		root     func(Result) (float64, bool)
+		fellBack bool
+	}{
+		{"two-root liquid", 300, 5, 0, PhaseLiquid, Result.Liquid, false},
+		{"two-root vapour", 300, 5, 0, PhaseVapour, Result.Vapour, false},
+		{"compressed liquid", 300, 100, 0, PhaseLiquid, Result.Liquid, false},
+		{"supercritical", 400, 50, 0, PhaseVapour, Result.Vapour, false},
+		// Newton converges on the other branch, or the branch is missing
+		{"liquid from a vapour guess", 300, 5, 4500, PhaseLiquid, Result.Liquid, true},
+		{"liquid of a vapour", 350, 1, 0, PhaseLiquid, Result.Vapour, true},
+		{"vapour of a liquid", 300, 100, 0, PhaseVapour, Result.Liquid, true},
+	} {
+		res, err := c.Solve(tc.T, tc.P)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, ok := tc.root(res)
+		if !ok {
+			t.Fatalf("%s: Solve found %s", tc.name, res.Phase)
+		}
+		d, err := c.Density(tc.T, tc.P, tc.phase, tc.v0)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if math.Abs(d.V/want-1) > 1e-9 || d.FellBack != tc.fellBack {
+			t.Errorf("%s: V = %g, fell back %t; want %g, %t", tc.name, d.V, d.FellBack, want, tc.fellBack)
+		}
+		if math.Abs(d.Z-tc.P*d.V/(barCm3R*tc.T)) > 1e-12 {
+			t.Errorf("%s: Z = %g does not match V", tc.name, d.Z)
+		}
+	}
+	if _, err := c.Density(300, 5, PhaseTwoRoot, 0); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("two-root phase: %v, want ErrInvalidInput", err)
+	}
+	if _, err := c.Density(-1, 5, PhaseLiquid, 0); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("negative T: %v, want ErrInvalidInput", err)
+	}
+}
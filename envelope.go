@@ -0,0 +1,496 @@
+package cubiceos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// EnvelopePoint is one converged point of a phase envelope or quality line
+type EnvelopePoint struct {
+	T, P float64
+	// X and Y are the compositions of the two phases in equilibrium,
+	// Y = K X. On the β = 0 line X is the feed; before the critical point
+	// Y is the incipient vapour (a bubble point), after it Y is the
+	// incipient liquid (a dew point).
+	X, Y []float64
+	LnK  []float64
+}
+
+// EnvelopeLine is a line of constant vapour fraction β traced across the
+// P–T plane
+type EnvelopeLine struct {
+	Beta   float64
+	Points []EnvelopePoint
+}
+
+// Envelope is the P–T phase envelope of a mixture of fixed composition
+type Envelope struct {
+	// Points traces the β = 0 line from PMin up the bubble branch, through
+	// the critical point and down the dew branch. Index Critical splits
+	// the two branches: Points[:Critical] are bubble points.
+	Points []EnvelopePoint
+	// Critical is the index of the first dew point, or len(Points) if the
+	// trace never crossed the critical point
+	Critical int
+	// CriticalT and CriticalP locate the critical point, interpolated
+	// where all ln K_i change sign; both are 0 if it was not crossed
+	CriticalT, CriticalP float64
+	// Cricondenbar and Cricondentherm are the maximum pressure and
+	// temperature of the envelope, each refined by a parabola through the
+	// three points around it
+	CricondenbarT, CricondenbarP     float64
+	CricondenthermT, CricondenthermP float64
+	Quality                          []EnvelopeLine //quality lines, in the order requested
+}
+
+// EnvelopeOptions controls PhaseEnvelope
+type EnvelopeOptions struct {
+	// PMin is the pressure the trace starts from and ends at. 0 selects 1%
+	// of the smallest component critical pressure.
+	PMin float64
+	// Qualities lists vapour fractions in (0, 1) whose quality lines are
+	// traced from PMin up to the critical point
+	Qualities []float64
+	// MaxPoints bounds the points on each line, 0 for 2000
+	MaxPoints int
+}
+
+const (
+	envNewtonIter = 30
+	envTol        = 1e-10
+	envMaxStep    = 0.25 //largest step in any variable
+)
+
+// envelopeLine is the Michelsen continuation state for one β. The
+// unknowns are X = (ln K_1 … ln K_n, ln T, ln P) and the equations
+//
+//	F_i     = ln K_i + ln φ_i(T, P, y) - ln φ_i(T, P, x)
+//	F_n+1   = Σ (y_i - x_i)
+//	F_n+2   = X_s - S
+//
+// with x_i = z_i/(1 - β + β K_i) and y_i = K_i x_i. The specified variable
+// s is switched along the line to the one changing fastest.
+type envelopeLine struct {
+	m    *Mixture
+	z    []float64
+	beta float64
+}
+
+func (e envelopeLine) phases(x []float64) (xs, ys []float64) {
+	n := len(e.z)
+	xs, ys = make([]float64, n), make([]float64, n)
+	for i := range n {
+		k := math.Exp(x[i])
+		xs[i] = e.z[i] / (1 - e.beta + e.beta*k)
+		ys[i] = k * xs[i]
+	}
+	return xs, ys
+}
+
+// residual evaluates the first n+1 equations
+func (e envelopeLine) residual(x []float64) ([]float64, error) {
+	n := len(e.z)
+	T, P := math.Exp(x[n]), math.Exp(x[n+1])
+	xs, ys := e.phases(x)
+	lnL, _, _, err := e.m.fugacity(T, P, normalise(xs), PhaseNone)
+	if err != nil {
+		return nil, err
+	}
+	lnV, _, _, err := e.m.fugacity(T, P, normalise(ys), PhaseNone)
+	if err != nil {
+		return nil, err
+	}
+	f := make([]float64, n+1)
+	for i := range n {
+		f[i] = x[i] + lnV[i] - lnL[i]
+		f[n] += ys[i] - xs[i]
+	}
+	return f, nil
+}
+
+// jacobian returns the (n+1)×(n+2) Jacobian of residual by central
+// differences
+func (e envelopeLine) jacobian(x []float64) ([][]float64, error) {
+	const h = 1e-6
+	n := len(e.z)
+	jac := make([][]float64, n+1)
+	for i := range jac {
+		jac[i] = make([]float64, n+2)
+	}
+	xp := append([]float64(nil), x...)
+	for j := range n + 2 {
+		xp[j] = x[j] + h
+		up, err := e.residual(xp)
+		if err != nil {
+			return nil, err
+		}
+		xp[j] = x[j] - h
+		down, err := e.residual(xp)
+		if err != nil {
+			return nil, err
+		}
+		xp[j] = x[j]
+		for i := range jac {
+			jac[i][j] = (up[i] - down[i]) / (2 * h)
+		}
+	}
+	return jac, nil
+}
+
+// full appends the specification row for variable s to jac
+func full(jac [][]float64, s int) [][]float64 {
+	out := append([][]float64(nil), jac...)
+	row := make([]float64, len(jac[0]))
+	row[s] = 1
+	return append(out, row)
+}
+
+// solve runs Newton's method from x with X_s held at its value in x. It
+// returns the converged point and the iterations taken.
+func (e envelopeLine) solve(x []float64, s int) ([]float64, int, error) {
+	x = append([]float64(nil), x...)
+	n := len(e.z)
+	for it := 1; it <= envNewtonIter; it++ {
+		f, err := e.residual(x)
+		if err != nil {
+			return nil, it, err
+		}
+		jac, err := e.jacobian(x)
+		if err != nil {
+			return nil, it, err
+		}
+		rhs := make([]float64, n+2)
+		for i := range f {
+			rhs[i] = -f[i]
+		}
+		dx, ok := solveLinear(full(jac, s), rhs)
+		if !ok {
+			return nil, it, errors.New("singular Jacobian")
+		}
+		// damp long steps
+		big := 0.0
+		for _, d := range dx {
+			big = math.Max(big, math.Abs(d))
+		}
+		scale := 1.0
+		if big > 1 {
+			scale = 1 / big
+		}
+		for i := range x {
+			x[i] += scale * dx[i]
+		}
+		if big*scale < envTol && maxAbs(f) < 1e-8 || maxAbs(f) < 1e-13 {
+			return x, it, nil
+		}
+	}
+	return nil, envNewtonIter, ErrNoConvergence
+}
+
+// sensitivity returns dX/dX_s at x with variable s specified
+func (e envelopeLine) sensitivity(x []float64, s int) ([]float64, error) {
+	jac, err := e.jacobian(x)
+	if err != nil {
+		return nil, err
+	}
+	rhs := make([]float64, len(x))
+	rhs[len(x)-1] = 1
+	v, ok := solveLinear(full(jac, s), rhs)
+	if !ok {
+		return nil, errors.New("singular Jacobian")
+	}
+	return v, nil
+}
+
+// start finds the first point of the line at pMin from Wilson K-values
+func (e envelopeLine) start(pMin float64) ([]float64, error) {
+	n := len(e.z)
+	wilson := func(T float64) ([]float64, float64) {
+		lnK := make([]float64, n)
+		g := 0.0
+		for i, c := range e.m.comps {
+			lnK[i] = math.Log(c.Pc/pMin) + 5.373*(1+c.W)*(1-c.Tc/T)
+			k := math.Exp(lnK[i])
+			g += e.z[i] * (k - 1) / (1 - e.beta + e.beta*k)
+		}
+		return lnK, g
+	}
+	// g rises with T; bisect in ln T
+	lo, hi := 1.0, 10000.0
+	for range 200 {
+		mid := math.Sqrt(lo * hi)
+		if _, g := wilson(mid); g > 0 {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	lnK, _ := wilson(math.Sqrt(lo * hi))
+	x := append(lnK, math.Log(math.Sqrt(lo*hi)), math.Log(pMin))
+	x, _, err := e.solve(x, n+1)
+	return x, err
+}
+
+// trace follows the line from pMin. With throughCritical it continues past
+// the critical point until the pressure falls back to pMin; otherwise it
+// stops at the critical point. It returns the points, the index of the
+// first point after the critical point and the interpolated critical T
+// and P (zero if not crossed).
+func (e envelopeLine) trace(pMin float64, maxPoints int, throughCritical bool) (pts [][]float64, crit int, tc, pc float64, err error) {
+	n := len(e.z)
+	x, err := e.start(pMin)
+	if err != nil {
+		return nil, 0, 0, 0, fmt.Errorf("starting point at P = %g: %w", pMin, err)
+	}
+	pts = [][]float64{x}
+	crit = -1
+
+	s := n + 1 // start by stepping in ln P
+	v, err := e.sensitivity(x, s)
+	if err != nil {
+		return pts, len(pts), 0, 0, err
+	}
+	step := 0.05
+	for len(pts) < maxPoints {
+		// switch to the fastest-changing variable, keeping the direction
+		best := s
+		for j := range v {
+			if math.Abs(v[j]) > math.Abs(v[best]) {
+				best = j
+			}
+		}
+		if best != s {
+			dir := math.Copysign(1, step*v[s])
+			scale := v[best]
+			for j := range v {
+				v[j] /= scale
+			}
+			s = best
+			step = dir * math.Copysign(math.Abs(step), v[s]*scale)
+			step = math.Copysign(math.Min(math.Abs(step), envMaxStep), step)
+		}
+		// limit the step so that no variable moves more than envMaxStep
+		big := 0.0
+		for _, d := range v {
+			big = math.Max(big, math.Abs(d*step))
+		}
+		if big > envMaxStep {
+			step *= envMaxStep / big
+		}
+
+		var next []float64
+		var it int
+		jumped := false
+		for {
+			// never land near the trivial solution K = 1, where spurious
+			// solutions with tiny ln K lie along the stability limit: jump
+			// once over the critical point to the mirror image of x in
+			// ln K_s instead
+			st := step
+			if s < n && !jumped && math.Abs(x[s]+v[s]*st) < 0.1*math.Abs(x[s]) {
+				st, jumped = -2*x[s]/v[s], true
+			}
+			guess := make([]float64, len(x))
+			for j := range x {
+				guess[j] = x[j] + v[j]*st
+			}
+			if s < n && math.Abs(guess[s]) < 1e-4 {
+				guess[s] = -x[s]
+			}
+			next, it, err = e.solve(guess, s)
+			if err == nil && maxAbs(next[:n]) > 1e-6 {
+				step = st
+				break
+			}
+			step /= 2
+			if math.Abs(step) < 1e-8 {
+				return pts, critIndex(crit, len(pts)), tc, pc, fmt.Errorf("%w: envelope step collapsed at T = %g, P = %g",
+					ErrNoConvergence, math.Exp(x[n]), math.Exp(x[n+1]))
+			}
+		}
+
+		vn, err := e.sensitivity(next, s)
+		if err != nil {
+			return pts, critIndex(crit, len(pts)), tc, pc, err
+		}
+
+		// the critical point lies where every ln K changes sign
+		if crit < 0 && crossed(x[:n], next[:n], e.z) {
+			crit = len(pts)
+			tc, pc = criticalBetween(x, next, v, vn, s, n)
+			if !throughCritical {
+				return pts, crit, tc, pc, nil
+			}
+		}
+
+		pts = append(pts, next)
+		if crit >= 0 && next[n+1] < math.Log(pMin) {
+			break
+		}
+		if it <= 3 {
+			step *= 1.5
+		} else if it > 6 {
+			step /= 2
+		}
+		x, v = next, vn
+	}
+	return pts, critIndex(crit, len(pts)), tc, pc, nil
+}
+
+func critIndex(crit, n int) int {
+	if crit < 0 {
+		return n
+	}
+	return crit
+}
+
+// crossed reports whether the largest ln K of a changed sign in b
+func crossed(a, b, z []float64) bool {
+	j := -1
+	for i := range a {
+		if z[i] > 0 && (j < 0 || math.Abs(a[i]) > math.Abs(a[j])) {
+			j = i
+		}
+	}
+	return j >= 0 && a[j]*b[j] < 0
+}
+
+// criticalBetween interpolates T and P where ln K vanishes between points
+// a and b, by cubic Hermite interpolation in the specified variable s when
+// it is a ln K and linearly in the largest ln K otherwise
+func criticalBetween(a, b, va, vb []float64, s, n int) (float64, float64) {
+	if s < n {
+		h := b[s] - a[s]
+		t := -a[s] / h
+		hermite := func(j int) float64 {
+			t2, t3 := t*t, t*t*t
+			return (2*t3-3*t2+1)*a[j] + (t3-2*t2+t)*h*va[j] + (-2*t3+3*t2)*b[j] + (t3-t2)*h*vb[j]
+		}
+		return math.Exp(hermite(n)), math.Exp(hermite(n + 1))
+	}
+	j := 0
+	for i := range n {
+		if math.Abs(a[i]) > math.Abs(a[j]) {
+			j = i
+		}
+	}
+	t := a[j] / (a[j] - b[j])
+	return math.Exp(a[n] + t*(b[n]-a[n])), math.Exp(a[n+1] + t*(b[n+1]-a[n+1]))
+}
+
+// PhaseEnvelope traces the P–T phase envelope of composition z with
+// Michelsen's continuation method: Newton's method on the equilibrium
+// equations in ln K, ln T and ln P with one variable specified, stepping
+// in whichever variable changes fastest, so the trace passes through the
+// critical point and the cricondenbar and cricondentherm without
+// difficulty. The Jacobian is formed numerically, so any EOS and mixing
+// rule can be used.
+func (m *Mixture) PhaseEnvelope(z []float64, opt EnvelopeOptions) (Envelope, error) {
+	z, err := m.composition(z)
+	if err != nil {
+		return Envelope{}, err
+	}
+	pMin := opt.PMin
+	if pMin == 0 {
+		pMin = math.Inf(1)
+		for _, c := range m.comps {
+			pMin = math.Min(pMin, 0.01*c.Pc)
+		}
+	}
+	if err := positive("PMin", pMin); err != nil {
+		return Envelope{}, err
+	}
+	maxPoints := opt.MaxPoints
+	if maxPoints <= 0 {
+		maxPoints = 2000
+	}
+	for i, b := range opt.Qualities {
+		if !(b > 0 && b < 1) {
+			return Envelope{}, &InvalidInputError{Field: fmt.Sprintf("Qualities[%d]", i), Value: b, Constraint: "in (0, 1)"}
+		}
+	}
+
+	n := len(z)
+	line := envelopeLine{m: m, z: z}
+	raw, crit, tc, pc, err := line.trace(pMin, maxPoints, true)
+	if len(raw) == 0 {
+		return Envelope{}, err
+	}
+	env := Envelope{Points: points(line, raw), Critical: crit, CriticalT: tc, CriticalP: pc}
+	env.CricondenbarT, env.CricondenbarP = extremum(raw, n+1, n)
+	env.CricondenthermP, env.CricondenthermT = extremum(raw, n, n+1)
+
+	for _, b := range opt.Qualities {
+		q := envelopeLine{m: m, z: z, beta: b}
+		qraw, _, _, _, qerr := q.trace(pMin, maxPoints, false)
+		env.Quality = append(env.Quality, EnvelopeLine{Beta: b, Points: points(q, qraw)})
+		if qerr != nil {
+			err = errors.Join(err, fmt.Errorf("quality line β = %g: %w", b, qerr))
+		}
+	}
+	// a partial envelope is still returned alongside the error
+	return env, err
+}
+
+func points(line envelopeLine, raw [][]float64) []EnvelopePoint {
+	n := len(line.z)
+	out := make([]EnvelopePoint, len(raw))
+	for k, x := range raw {
+		xs, ys := line.phases(x)
+		out[k] = EnvelopePoint{
+			T:   math.Exp(x[n]),
+			P:   math.Exp(x[n+1]),
+			X:   normalise(xs),
+			Y:   normalise(ys),
+			LnK: append([]float64(nil), x[:n]...),
+		}
+	}
+	return out
+}
+
+// extremum returns the maximum of variable j over the points and the
+// matching value of variable k, refined by fitting X_j as a parabola in
+// X_k through the maximum point and its neighbours. Both are returned as
+// exp of the log variables.
+func extremum(raw [][]float64, j, k int) (atK, maxJ float64) {
+	best := 0
+	for i, x := range raw {
+		if x[j] > raw[best][j] {
+			best = i
+		}
+	}
+	if best == 0 || best == len(raw)-1 {
+		return math.Exp(raw[best][k]), math.Exp(raw[best][j])
+	}
+	x0, x1, x2 := raw[best-1][k], raw[best][k], raw[best+1][k]
+	y0, y1, y2 := raw[best-1][j], raw[best][j], raw[best+1][j]
+	d := (x0 - x1) * (x0 - x2) * (x1 - x2)
+	a := (x2*(y1-y0) + x1*(y0-y2) + x0*(y2-y1)) / d
+	b := (x2*x2*(y0-y1) + x1*x1*(y2-y0) + x0*x0*(y1-y2)) / d
+	c := (x1*x2*(x1-x2)*y0 + x2*x0*(x2-x0)*y1 + x0*x1*(x0-x1)*y2) / d
+	if !(a < 0) || !isFinite(a) {
+		return math.Exp(x1), math.Exp(y1)
+	}
+	xv := -b / (2 * a)
+	return math.Exp(xv), math.Exp(c - b*b/(4*a))
+}
+
+func normalise(x []float64) []float64 {
+	sum := 0.0
+	for _, v := range x {
+		sum += v
+	}
+	out := make([]float64, len(x))
+	for i, v := range x {
+		out[i] = v / sum
+	}
+	return out
+}
+
+func maxAbs(x []float64) float64 {
+	m := 0.0
+	for _, v := range x {
+		m = math.Max(m, math.Abs(v))
+	}
+	return m
+}
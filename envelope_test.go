@@ -0,0 +1,56 @@
+package cubiceos
+
+import (
+	"math"
+	"testing"
+)
+
+// ternaryPR is methane/propane/n-decane with Peng–Robinson, in bar and
+// cm³, and the feed used by the envelope and critical point tests
+func ternaryPR(t *testing.T) (*Mixture, []float64) {
+	t.Helper()
+	m, err := NewMixture(PR{}, []Component{
+		{Name: "methane", Tc: 190.6, Pc: 46.0, W: 0.012},
+		{Name: "propane", Tc: 369.8, Pc: 42.48, W: 0.152},
+		{Name: "n-decane", Tc: 617.7, Pc: 21.1, W: 0.49},
+	}, nil, barCm3R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, []float64{0.6, 0.3, 0.1}
+}
+
+func TestPhaseEnvelopeTernary(t *testing.T) {
+	m, z := ternaryPR(t)
+	env, err := m.PhaseEnvelope(z, EnvelopeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name      string
+		got, want float64
+		tol       float64
+	}{
+		{"critical T", env.CriticalT, 401.24, 0.1},
+		{"critical P", env.CriticalP, 190.77, 0.1},
+		{"cricondentherm T", env.CricondenthermT, 489.3, 0.2},
+		{"cricondenbar P", env.CricondenbarP, 193.2, 0.2},
+	} {
+		if math.Abs(c.got-c.want) > c.tol {
+			t.Errorf("%s = %g, want %g ± %g", c.name, c.got, c.want, c.tol)
+		}
+	}
+	if env.Critical == 0 || env.Critical == len(env.Points) {
+		t.Errorf("critical index %d of %d points: the trace did not cross the critical point", env.Critical, len(env.Points))
+	}
+	// the cricondentherm lies on the dew branch
+	hottest := 0
+	for i, p := range env.Points {
+		if p.T > env.Points[hottest].T {
+			hottest = i
+		}
+	}
+	if hottest < env.Critical {
+		t.Errorf("hottest point %d precedes the critical index %d", hottest, env.Critical)
+	}
+}
@@ -0,0 +1,113 @@
+package cubiceos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrInvalidInput is matched by every *InvalidInputError via errors.Is
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrNoEOSType is returned when a configuration has no EOSType set
+	ErrNoEOSType = errors.New("no equation of state type set")
+	// ErrNoPhysicalRoot is returned when the EOS has no root with V > b
+	ErrNoPhysicalRoot = errors.New("no physically meaningful root")
+	// ErrNotCubic is returned by SolveCubic when the leading coefficient is 0
+	ErrNotCubic = errors.New("equation provided is not cubic (a = 0)")
+	// ErrNoConvergence is returned when an iterative calculation runs out
+	// of iterations
+	ErrNoConvergence = errors.New("iteration did not converge")
+	// ErrNoCriticalPoint is returned when a mixture has no critical point
+	// within the volumes searched
+	ErrNoCriticalPoint = errors.New("no critical point found")
+	// ErrUnsupported is returned when a calculation is not available for
+	// the equation of state, e.g. the reduced form of CPA
+	ErrUnsupported = errors.New("not supported by the equation of state")
+)
+
+// InvalidInputError reports a single input that violates a constraint
+type InvalidInputError struct {
+	Field      string  //name of the offending field, e.g. "Tc"
+	Value      float64 //value that was supplied
+	Constraint string  //what the value must satisfy, e.g. "> 0"
+}
+
+func (e *InvalidInputError) Error() string {
+	return fmt.Sprintf("%s must be %s (got %g)", e.Field, e.Constraint, e.Value)
+}
+
+// Is makes errors.Is(err, ErrInvalidInput) true for any InvalidInputError
+func (e *InvalidInputError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// InputErrors returns every *InvalidInputError contained in err, including
+// those joined together by Validate. It returns nil if there are none.
+func InputErrors(err error) []*InvalidInputError {
+	var out []*InvalidInputError
+	var walk func(error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case *InvalidInputError:
+			out = append(out, e)
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+	return out
+}
+
+// positive checks that v is finite and strictly positive
+func positive(field string, v float64) error {
+	if !isFinite(v) || v <= 0 {
+		return &InvalidInputError{Field: field, Value: v, Constraint: "> 0"}
+	}
+	return nil
+}
+
+// isFinite reports whether v is neither NaN nor infinite
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// finite checks that v is neither NaN nor infinite
+func finite(field string, v float64) error {
+	if !isFinite(v) {
+		return &InvalidInputError{Field: field, Value: v, Constraint: "finite"}
+	}
+	return nil
+}
+
+// Validate checks every field of cfg and reports all violations at once.
+// The returned error matches ErrInvalidInput and can be split into its
+// individual *InvalidInputError values with InputErrors.
+func (cfg EOSCfg) Validate() error {
+	return errors.Join(
+		validateType(cfg.Type),
+		positive("T", cfg.T),
+		positive("P", cfg.P),
+		positive("Tc", cfg.Tc),
+		positive("Pc", cfg.Pc),
+		finite("W", cfg.W),
+		positive("R", cfg.R),
+	)
+}
+
+// validateType checks that eos is set and runs its own checks, if it has
+// a validate method
+func validateType(eos EOSType) error {
+	if eos == nil {
+		return ErrNoEOSType
+	}
+	if v, ok := eos.(interface{ validate() error }); ok {
+		return v.validate()
+	}
+	return nil
+}
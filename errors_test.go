@@ -0,0 +1,41 @@
+package cubiceos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestValidateReportsEveryField(t *testing.T) {
+	cfg := EOSCfg{T: -1, P: 0, Tc: 369.8, Pc: math.Inf(1), W: math.NaN(), R: barCm3R}
+	err := cfg.Validate()
+	if !errors.Is(err, ErrNoEOSType) || !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Validate: %v, want ErrNoEOSType and ErrInvalidInput", err)
+	}
+	var first *InvalidInputError
+	if !errors.As(err, &first) || first.Field != "T" || first.Value != -1 {
+		t.Errorf("errors.As: %+v, want the T error", first)
+	}
+	got := InputErrors(err)
+	want := []string{"T", "P", "Pc", "W"}
+	if len(got) != len(want) {
+		t.Fatalf("InputErrors: %d errors %v, want fields %v", len(got), got, want)
+	}
+	for i, e := range got {
+		if e.Field != want[i] {
+			t.Errorf("InputErrors[%d] is %s, want %s", i, e.Field, want[i])
+		}
+	}
+
+	// through further wrapping, and for a valid configuration
+	if got := InputErrors(fmt.Errorf("solving: %w", err)); len(got) != len(want) {
+		t.Errorf("InputErrors of a wrapped error: %d errors, want %d", len(got), len(want))
+	}
+	if err := propaneCfg.Validate(); err != nil || InputErrors(err) != nil {
+		t.Errorf("valid configuration: %v", err)
+	}
+	if _, err := Solve(cfg); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Solve: %v, want ErrInvalidInput", err)
+	}
+}
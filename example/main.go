@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/rickykimani/cubiceos"
@@ -14,9 +15,9 @@ func main() {
 		37.96,  // Pc (bar)
 		83.14,  // R (bar•cm^3/(mol•K))
 	)
-	res, err := cubiceos.CubicEOS(eq)
+	res, err := cubiceos.Solve(eq)
 	if err != nil {
 		log.Fatal(err)
 	}
-	cubiceos.ResultPrinter(res)
+	fmt.Println(res)
 }
@@ -0,0 +1,174 @@
+package cubiceos
+
+import (
+	"fmt"
+	"math"
+)
+
+// Deviations summarises the deviations d_k of a fit from its data
+type Deviations struct {
+	N    int
+	AAD  float64 //average absolute deviation, mean |d_k|
+	Bias float64 //mean d_k
+	Max  float64 //max |d_k|
+}
+
+// deviations summarises d, skipping NaN entries (unmeasured data)
+func deviations(d []float64) Deviations {
+	var s Deviations
+	for _, v := range d {
+		if math.IsNaN(v) {
+			continue
+		}
+		s.N++
+		s.AAD += math.Abs(v)
+		s.Bias += v
+		s.Max = math.Max(s.Max, math.Abs(v))
+	}
+	if s.N > 0 {
+		s.AAD /= float64(s.N)
+		s.Bias /= float64(s.N)
+	}
+	return s
+}
+
+const (
+	lmMaxIter = 200
+	lmTol     = 1e-10
+)
+
+// lmResult is a converged least-squares fit
+type lmResult struct {
+	p          []float64   //parameters
+	r          []float64   //residuals at p
+	jac        [][]float64 //∂r_k/∂p_j at p
+	iterations int
+}
+
+// levenbergMarquardt minimises Σ r_k(p)² from p, with a central-difference
+// Jacobian and Marquardt's diagonal scaling. A residual function error
+// rejects the step, as a rise in the sum of squares does.
+func levenbergMarquardt(f func([]float64) ([]float64, error), p []float64) (lmResult, error) {
+	p = append([]float64(nil), p...)
+	r, err := f(p)
+	if err != nil {
+		return lmResult{}, fmt.Errorf("at the initial parameters: %w", err)
+	}
+	ssr := sumSquares(r)
+	lambda := 1e-3
+	for it := 1; it <= lmMaxIter; it++ {
+		jac, err := numericJacobian(f, p, len(r))
+		if err != nil {
+			return lmResult{}, err
+		}
+		n := len(p)
+		a := make([][]float64, n)
+		g := make([]float64, n)
+		for i := range n {
+			a[i] = make([]float64, n)
+			for k := range r {
+				g[i] -= jac[k][i] * r[k]
+				for j := range n {
+					a[i][j] += jac[k][i] * jac[k][j]
+				}
+			}
+		}
+		for {
+			damped := cloneMatrix(a)
+			for i := range n {
+				damped[i][i] += lambda * math.Max(a[i][i], 1e-12)
+			}
+			step, ok := solveLinear(damped, g)
+			if ok {
+				trial := make([]float64, n)
+				for i := range n {
+					trial[i] = p[i] + step[i]
+				}
+				if rt, err := f(trial); err == nil && sumSquares(rt) <= ssr {
+					done := maxAbs(step) <= lmTol*(1+maxAbs(p)) || ssr-sumSquares(rt) <= lmTol*lmTol*ssr
+					p, r, ssr = trial, rt, sumSquares(rt)
+					lambda = math.Max(lambda/10, 1e-12)
+					if done {
+						jac, err := numericJacobian(f, p, len(r))
+						return lmResult{p: p, r: r, jac: jac, iterations: it}, err
+					}
+					break
+				}
+			}
+			lambda *= 10
+			if lambda > 1e12 {
+				// no downhill step remains: p is a minimum to within the
+				// accuracy of the residuals
+				return lmResult{p: p, r: r, jac: jac, iterations: it}, nil
+			}
+		}
+	}
+	return lmResult{}, fmt.Errorf("%w: Levenberg–Marquardt after %d iterations", ErrNoConvergence, lmMaxIter)
+}
+
+func numericJacobian(f func([]float64) ([]float64, error), p []float64, m int) ([][]float64, error) {
+	jac := make([][]float64, m)
+	for k := range jac {
+		jac[k] = make([]float64, len(p))
+	}
+	q := append([]float64(nil), p...)
+	for j := range p {
+		h := 1e-6 * math.Max(1, math.Abs(p[j]))
+		q[j] = p[j] + h
+		up, err := f(q)
+		if err != nil {
+			return nil, err
+		}
+		q[j] = p[j] - h
+		down, err := f(q)
+		if err != nil {
+			return nil, err
+		}
+		q[j] = p[j]
+		for k := range jac {
+			jac[k][j] = (up[k] - down[k]) / (2 * h)
+		}
+	}
+	return jac, nil
+}
+
+// stdErr returns the standard errors of the parameters from the
+// covariance s²(JᵀJ)⁻¹, s² = Σ r²/(m - n); NaN where they are undefined
+// (no degrees of freedom or a singular JᵀJ)
+func (l lmResult) stdErr() []float64 {
+	n, m := len(l.p), len(l.r)
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	if m <= n {
+		return out
+	}
+	a := make([][]float64, n)
+	for i := range n {
+		a[i] = make([]float64, n)
+		for k := range m {
+			for j := range n {
+				a[i][j] += l.jac[k][i] * l.jac[k][j]
+			}
+		}
+	}
+	s2 := sumSquares(l.r) / float64(m-n)
+	for i := range n {
+		e := make([]float64, n)
+		e[i] = 1
+		col, ok := solveLinear(a, e)
+		if ok && col[i] >= 0 {
+			out[i] = math.Sqrt(s2 * col[i])
+		}
+	}
+	return out
+}
+
+func sumSquares(r []float64) float64 {
+	s := 0.0
+	for _, v := range r {
+		s += v * v
+	}
+	return s
+}
@@ -0,0 +1,460 @@
+package cubiceos
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// FlashPhase is one phase of a flash result
+type FlashPhase struct {
+	Kind  Phase     //PhaseLiquid or PhaseVapour, by the EOS root used
+	Beta  float64   //fraction of the feed moles in this phase
+	X     []float64 //mole fractions
+	LnPhi []float64 //component ln fugacity coefficients
+	Z     float64
+	V     float64 //molar volume
+}
+
+// FlashResult is the equilibrium state of a feed at fixed T and P
+type FlashResult struct {
+	T, P float64
+	// Phases holds the phases present, vapour first and then the liquids
+	// by increasing density
+	Phases     []FlashPhase
+	Iterations int //successive-substitution iterations over all stages
+}
+
+const (
+	flashMaxPhases = 3
+	flashMaxIter   = 1000
+	flashTol       = 1e-10
+	// stabilityTol is how negative the tangent plane distance must be for
+	// a phase to be declared unstable
+	stabilityTol = 1e-8
+)
+
+// Flash finds the equilibrium phases of feed z at T and P, up to three
+// (vapour–liquid–liquid). Phases are added one at a time: Michelsen's
+// tangent-plane stability test is run on the current solution and, if it
+// is unstable, the most unstable trial composition seeds a new phase.
+// Each phase set is converged by successive substitution on the fugacity
+// coefficients, with the phase amounts from the multiphase
+// Rachford–Rice problem in Michelsen's convex form
+//
+//	min Q(β) = Σ_k β_k - Σ_i z_i ln Σ_k β_k/φ_ik,  β_k >= 0
+//
+// which removes phases that vanish by driving their β to 0. If the phase
+// just seeded vanishes while the phases left are still unstable, the
+// error wraps ErrNoConvergence.
+//
+// Any EOS and mixing rule can be used; the root of each phase is the one
+// with the lower Gibbs energy.
+func (m *Mixture) Flash(T, P float64, z []float64) (FlashResult, error) {
+	if err := errors.Join(positive("T", T), positive("P", P)); err != nil {
+		return FlashResult{}, err
+	}
+	z, err := m.composition(z)
+	if err != nil {
+		return FlashResult{}, err
+	}
+
+	f := flash{m: m, T: T, P: P, z: z, xs: [][]float64{z}, beta: []float64{1}}
+	for {
+		seeded := len(f.xs)
+		if err := f.converge(); err != nil {
+			return FlashResult{}, err
+		}
+		if len(f.xs) >= min(flashMaxPhases, len(z)) {
+			break
+		}
+		w, tm, err := m.stability(T, P, f.xs[0], f.lnPhi[0])
+		if err != nil {
+			return FlashResult{}, err
+		}
+		if tm >= -stabilityTol {
+			break
+		}
+		// the phase seeded by the last stability test vanished, yet the
+		// phases left are still unstable
+		if len(f.xs) < seeded {
+			return FlashResult{}, fmt.Errorf("%w: flash at T = %g, P = %g lost the phase seeded by the stability test and is still unstable (tm = %g)",
+				ErrNoConvergence, T, P, tm)
+		}
+		f.xs = append(f.xs, w)
+		f.beta = append(f.beta, 0)
+	}
+
+	res := FlashResult{T: T, P: P, Iterations: f.iter}
+	for k, x := range f.xs {
+		res.Phases = append(res.Phases, FlashPhase{
+			Kind:  f.kinds[k],
+			Beta:  f.beta[k],
+			X:     x,
+			LnPhi: f.lnPhi[k],
+			Z:     f.zs[k],
+			V:     f.zs[k] * m.r * T / P,
+		})
+	}
+	slices.SortFunc(res.Phases, func(a, b FlashPhase) int { return cmp.Compare(b.V, a.V) })
+	return res, nil
+}
+
+// flash is the state of a multiphase flash: the phase compositions, their
+// amounts and, once converged, their fugacity coefficients
+type flash struct {
+	m     *Mixture
+	T, P  float64
+	z     []float64
+	xs    [][]float64
+	beta  []float64
+	lnPhi [][]float64
+	zs    []float64
+	kinds []Phase
+	iter  int
+}
+
+// evaluate computes the fugacity coefficients of every phase
+func (f *flash) evaluate() error {
+	np := len(f.xs)
+	f.lnPhi, f.zs, f.kinds = make([][]float64, np), make([]float64, np), make([]Phase, np)
+	for k, x := range f.xs {
+		lnPhi, z, kind, err := f.m.fugacity(f.T, f.P, x, PhaseNone)
+		if err != nil {
+			return err
+		}
+		f.lnPhi[k], f.zs[k], f.kinds[k] = lnPhi, z, kind
+	}
+	return nil
+}
+
+// converge iterates the phase compositions to equilibrium for the current
+// set of phases, dropping those that vanish or merge
+func (f *flash) converge() error {
+	if err := f.evaluate(); err != nil {
+		return err
+	}
+	if len(f.xs) == 1 {
+		return nil
+	}
+	for range flashMaxIter {
+		f.iter++
+		phi := make([][]float64, len(f.xs))
+		for k, lnPhi := range f.lnPhi {
+			phi[k] = make([]float64, len(lnPhi))
+			for i, v := range lnPhi {
+				phi[k][i] = math.Exp(v)
+			}
+		}
+		splitPhases(f.z, phi, f.beta)
+
+		// x_ik = z_i/(φ_ik E_i), E_i = Σ_k β_k/φ_ik
+		e := make([]float64, len(f.z))
+		for i := range f.z {
+			for k := range f.beta {
+				e[i] += f.beta[k] / phi[k][i]
+			}
+		}
+		for k := range f.xs {
+			x := make([]float64, len(f.z))
+			sum := 0.0
+			for i := range f.z {
+				x[i] = f.z[i] / (phi[k][i] * e[i])
+				sum += x[i]
+			}
+			for i := range x {
+				x[i] /= sum
+			}
+			f.xs[k] = x
+		}
+
+		prev := f.lnPhi
+		if err := f.evaluate(); err != nil {
+			return err
+		}
+		change := 0.0
+		for k := range prev {
+			for i := range prev[k] {
+				if f.z[i] > 0 {
+					change = math.Max(change, math.Abs(f.lnPhi[k][i]-prev[k][i]))
+				}
+			}
+		}
+		if f.merge() {
+			continue
+		}
+		if change < flashTol {
+			f.drop()
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: flash at T = %g, P = %g", ErrNoConvergence, f.T, f.P)
+}
+
+// merge combines two phases that have converged to the same state, the
+// trivial solution, and reports whether it did
+func (f *flash) merge() bool {
+	for k := range f.xs {
+		for l := k + 1; l < len(f.xs); l++ {
+			if f.kinds[k] != f.kinds[l] || maxAbsDiff(f.xs[k], f.xs[l]) > 1e-6 {
+				continue
+			}
+			f.beta[k] += f.beta[l]
+			f.remove(l)
+			return true
+		}
+	}
+	return false
+}
+
+// drop removes phases with no amount
+func (f *flash) drop() {
+	for k := len(f.xs) - 1; k >= 0 && len(f.xs) > 1; k-- {
+		if f.beta[k] == 0 {
+			f.remove(k)
+		}
+	}
+}
+
+func (f *flash) remove(k int) {
+	f.xs = slices.Delete(f.xs, k, k+1)
+	f.beta = slices.Delete(f.beta, k, k+1)
+	f.lnPhi = slices.Delete(f.lnPhi, k, k+1)
+	f.zs = slices.Delete(f.zs, k, k+1)
+	f.kinds = slices.Delete(f.kinds, k, k+1)
+}
+
+// splitPhases minimises Michelsen's Q(β) for fixed fugacity coefficients
+// phi[k][i] by projected Newton steps, updating beta in place. Q is convex,
+// with gradient g_k = 1 - Σ_i z_i/(φ_ik E_i) and Hessian
+// H_kl = Σ_i z_i/(φ_ik φ_il E_i²).
+func splitPhases(z []float64, phi [][]float64, beta []float64) {
+	np := len(beta)
+	q := func(beta []float64) float64 {
+		v := 0.0
+		for _, b := range beta {
+			v += b
+		}
+		for i := range z {
+			if z[i] == 0 {
+				continue
+			}
+			e := 0.0
+			for k := range beta {
+				e += beta[k] / phi[k][i]
+			}
+			v -= z[i] * math.Log(e)
+		}
+		return v
+	}
+
+	for range 100 {
+		e := make([]float64, len(z))
+		for i := range z {
+			for k := range np {
+				e[i] += beta[k] / phi[k][i]
+			}
+		}
+		g := make([]float64, np)
+		for k := range np {
+			g[k] = 1
+			for i := range z {
+				if z[i] > 0 {
+					g[k] -= z[i] / (phi[k][i] * e[i])
+				}
+			}
+		}
+
+		// phases held at β = 0 by a non-negative gradient are inactive
+		var free []int
+		worst := 0.0
+		for k := range np {
+			if beta[k] > 0 || g[k] < 0 {
+				free = append(free, k)
+				worst = math.Max(worst, math.Abs(g[k]))
+			}
+		}
+		if worst < 1e-14 {
+			return
+		}
+		h := make([][]float64, len(free))
+		rhs := make([]float64, len(free))
+		for a, k := range free {
+			h[a] = make([]float64, len(free))
+			for b, l := range free {
+				for i := range z {
+					h[a][b] += z[i] / (phi[k][i] * phi[l][i] * e[i] * e[i])
+				}
+			}
+			rhs[a] = -g[k]
+		}
+		step, ok := solveLinear(h, rhs)
+		if !ok {
+			step = rhs
+		}
+
+		// longest step that keeps β >= 0, then backtrack on Q
+		s := 1.0
+		for a, k := range free {
+			if step[a] < 0 && beta[k]+step[a] < 0 {
+				s = math.Min(s, -beta[k]/step[a])
+			}
+		}
+		q0 := q(beta)
+		next := slices.Clone(beta)
+		for range 50 {
+			for a, k := range free {
+				next[k] = math.Max(0, beta[k]+s*step[a])
+			}
+			if q(next) <= q0 {
+				break
+			}
+			s /= 2
+		}
+		copy(beta, next)
+	}
+}
+
+// stability runs Michelsen's tangent-plane stability test on phase x with
+// component ln fugacity coefficients lnPhi. Successive substitution on
+//
+//	ln W_i = ln x_i + ln φ_i(x) - ln φ_i(W)
+//
+// is started from vapour-like and liquid-like Wilson K-value trials and
+// from a near-pure trial of each component. It returns the normalised
+// composition of the trial with the lowest modified tangent plane distance
+// tm = 1 + Σ W_i (ln W_i + ln φ_i(W) - ln x_i - ln φ_i(x) - 1), and that
+// distance; x is unstable when tm < 0.
+func (m *Mixture) stability(T, P float64, x, lnPhi []float64) (w []float64, tm float64, err error) {
+	n := len(x)
+	d := make([]float64, n)
+	for i := range n {
+		d[i] = math.Log(x[i]) + lnPhi[i]
+	}
+
+	var trials [][]float64
+	vap, liq := make([]float64, n), make([]float64, n)
+	for i, c := range m.comps {
+		k := c.Pc / P * math.Exp(5.373*(1+c.W)*(1-c.Tc/T))
+		vap[i], liq[i] = x[i]*k, x[i]/k
+	}
+	trials = append(trials, vap, liq)
+	for i := range n {
+		pure := make([]float64, n)
+		for j := range pure {
+			pure[j] = 0.001 / float64(n)
+		}
+		pure[i] = 0.999
+		trials = append(trials, pure)
+	}
+
+	tm = math.Inf(1)
+	for _, trial := range trials {
+		wt, tmt, err := m.stabilityTrial(T, P, x, d, trial)
+		if err != nil {
+			return nil, 0, err
+		}
+		if wt != nil && tmt < tm {
+			w, tm = wt, tmt
+		}
+	}
+	if w == nil {
+		return nil, 0, nil
+	}
+	return w, tm, nil
+}
+
+// stabilityTrial converges one stability trial. It returns nil if the
+// trial collapses onto x, the trivial solution.
+func (m *Mixture) stabilityTrial(T, P float64, x, d, w []float64) ([]float64, float64, error) {
+	n := len(x)
+	w = slices.Clone(w)
+	tm := 0.0
+	for range flashMaxIter {
+		sum := 0.0
+		for _, v := range w {
+			sum += v
+		}
+		y := make([]float64, n)
+		for i := range y {
+			y[i] = w[i] / sum
+		}
+		if maxAbsDiff(y, x) < 1e-6 {
+			return nil, 0, nil
+		}
+		lnPhi, _, _, err := m.fugacity(T, P, y, PhaseNone)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		tm = 1
+		change := 0.0
+		for i := range n {
+			if w[i] == 0 {
+				continue
+			}
+			tm += w[i] * (math.Log(w[i]) + lnPhi[i] - d[i] - 1)
+			next := math.Exp(d[i] - lnPhi[i])
+			change = math.Max(change, math.Abs(math.Log(next/w[i])))
+			w[i] = next
+		}
+		if change < flashTol {
+			break
+		}
+	}
+	sum := 0.0
+	for _, v := range w {
+		sum += v
+	}
+	for i := range w {
+		w[i] /= sum
+	}
+	return w, tm, nil
+}
+
+// solveLinear solves a x = b by Gaussian elimination with partial
+// pivoting, reporting false if a is singular
+func solveLinear(a [][]float64, b []float64) ([]float64, bool) {
+	n := len(b)
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = append(slices.Clone(a[i]), b[i])
+	}
+	for c := range n {
+		p := c
+		for r := c + 1; r < n; r++ {
+			if math.Abs(m[r][c]) > math.Abs(m[p][c]) {
+				p = r
+			}
+		}
+		if m[p][c] == 0 || !isFinite(m[p][c]) {
+			return nil, false
+		}
+		m[c], m[p] = m[p], m[c]
+		for r := c + 1; r < n; r++ {
+			f := m[r][c] / m[c][c]
+			for k := c; k <= n; k++ {
+				m[r][k] -= f * m[c][k]
+			}
+		}
+	}
+	x := make([]float64, n)
+	for r := n - 1; r >= 0; r-- {
+		s := m[r][n]
+		for k := r + 1; k < n; k++ {
+			s -= m[r][k] * x[k]
+		}
+		x[r] = s / m[r][r]
+	}
+	return x, true
+}
+
+func maxAbsDiff(a, b []float64) float64 {
+	d := 0.0
+	for i := range a {
+		d = math.Max(d, math.Abs(a[i]-b[i]))
+	}
+	return d
+}
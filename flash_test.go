@@ -0,0 +1,75 @@
+package cubiceos
+
+import (
+	"math"
+	"testing"
+)
+
+// checkFlash checks the material balance and the equality of each
+// component's fugacity across the phases of res
+func checkFlash(t *testing.T, res FlashResult, z []float64) {
+	t.Helper()
+	for i := range z {
+		sum := 0.0
+		for _, p := range res.Phases {
+			sum += p.Beta * p.X[i]
+		}
+		if math.Abs(sum-z[i]) > 1e-9 {
+			t.Errorf("component %d: phases hold %g of feed %g", i, sum, z[i])
+		}
+		ref := res.Phases[0]
+		for _, p := range res.Phases[1:] {
+			d := math.Log(p.X[i]) + p.LnPhi[i] - math.Log(ref.X[i]) - ref.LnPhi[i]
+			if math.Abs(d) > 1e-7 {
+				t.Errorf("component %d: ln fugacities differ by %g", i, d)
+			}
+		}
+	}
+}
+
+func TestFlashTwoPhase(t *testing.T) {
+	m, z := ternaryPR(t)
+	res, err := m.Flash(300, 50, z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Phases) != 2 || res.Phases[0].Kind != PhaseVapour || res.Phases[1].Kind != PhaseLiquid {
+		t.Fatalf("phases %+v, want vapour and liquid", res.Phases)
+	}
+	if v := res.Phases[0]; math.Abs(v.Beta-0.63469) > 1e-4 || math.Abs(v.X[0]-0.81616) > 1e-4 {
+		t.Errorf("vapour β = %g, methane %g; want 0.63469, 0.81616", v.Beta, v.X[0])
+	}
+	checkFlash(t, res, z)
+}
+
+func TestFlashThreePhase(t *testing.T) {
+	// water and hydrocarbons are nearly immiscible with k_ij = 0.5
+	K := [][]float64{{0, 0.5, 0.5}, {0.5, 0, 0.04}, {0.5, 0.04, 0}}
+	m, err := NewMixture(PR{}, []Component{
+		{Name: "water", Tc: 647.1, Pc: 220.64, W: 0.344},
+		{Name: "methane", Tc: 190.6, Pc: 46.0, W: 0.012},
+		{Name: "n-decane", Tc: 617.7, Pc: 21.1, W: 0.49},
+	}, Classical{K: K}, barCm3R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := []float64{0.5, 0.3, 0.2}
+	res, err := m.Flash(350, 50, z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Phases) != 3 {
+		t.Fatalf("%d phases, want vapour, oil and water: %+v", len(res.Phases), res.Phases)
+	}
+	gas, oil, water := res.Phases[0], res.Phases[1], res.Phases[2]
+	if gas.Kind != PhaseVapour || oil.Kind != PhaseLiquid || water.Kind != PhaseLiquid {
+		t.Errorf("phase kinds %v, %v, %v", gas.Kind, oil.Kind, water.Kind)
+	}
+	if gas.X[1] < 0.98 || oil.X[2] < 0.8 || water.X[0] < 0.9999 {
+		t.Errorf("gas methane %g, oil decane %g, water %g", gas.X[1], oil.X[2], water.X[0])
+	}
+	if math.Abs(water.Beta-0.49693) > 1e-4 {
+		t.Errorf("water phase β = %g, want 0.49693", water.Beta)
+	}
+	checkFlash(t, res, z)
+}
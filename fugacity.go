@@ -0,0 +1,21 @@
+package cubiceos
+
+import "math"
+
+// integralI evaluates the departure-function integral
+//
+//	I = 1/(σ-ε) ln((Z + σβ)/(Z + εβ))
+//
+// which reduces to β/(Z + εβ) when σ = ε (e.g. van der Waals)
+func integralI(p Params, z, beta float64) float64 {
+	if p.Sigma == p.Epsilon {
+		return beta / (z + p.Epsilon*beta)
+	}
+	return math.Log((z+p.Sigma*beta)/(z+p.Epsilon*beta)) / (p.Sigma - p.Epsilon)
+}
+
+// dPdV returns the isothermal slope of the pressure-explicit EOS at v
+func dPdV(p Params, a, b, T, R, v float64) float64 {
+	d := (v + p.Epsilon*b) * (v + p.Sigma*b)
+	return -R*T/((v-b)*(v-b)) + a*(2*v+(p.Epsilon+p.Sigma)*b)/(d*d)
+}
@@ -0,0 +1,256 @@
+package cubiceos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// GasZCorrelation selects a natural gas Z-factor correlation
+type GasZCorrelation int
+
+const (
+	// DranchukAbouKassem is the 11-constant fit of Dranchuk and Abou-Kassem
+	// (1975) to the Standing–Katz chart
+	DranchukAbouKassem GasZCorrelation = iota
+	// HallYarborough is the Starling–Carnahan based fit of Hall and
+	// Yarborough (1973)
+	HallYarborough
+	// Papay is the explicit correlation of Papay (1968), for low
+	// pressures only (Ppr <= 1)
+	Papay
+)
+
+// GasZCorrelations lists every correlation, for side-by-side comparison
+var GasZCorrelations = []GasZCorrelation{DranchukAbouKassem, HallYarborough, Papay}
+
+func (c GasZCorrelation) String() string {
+	switch c {
+	case DranchukAbouKassem:
+		return "Dranchuk-Abou-Kassem"
+	case HallYarborough:
+		return "Hall-Yarborough"
+	case Papay:
+		return "Papay"
+	default:
+		return fmt.Sprintf("GasZCorrelation(%d)", int(c))
+	}
+}
+
+const (
+	gasZMaxIter = 100
+	gasZTol     = 1e-12
+	// papayMaxPpr bounds Papay's range. Up to it the correlation is within
+	// 2% of Dranchuk–Abou-Kassem for Tpr >= 1.2; beyond it the quadratic
+	// in Ppr turns up and runs away (Z = 3.96 against 1.64 at Tpr = 1.2,
+	// Ppr = 15).
+	papayMaxPpr = 1.0
+)
+
+// GasZ returns the Z factor of a natural gas at pseudo-reduced temperature
+// Tpr and pressure Ppr. The correlations represent the Standing–Katz chart
+// and need Tpr >= 1; Dranchuk–Abou-Kassem and Hall–Yarborough were fitted
+// for Tpr up to 3 and Ppr up to 30, and Papay is limited to Ppr <= 1.
+// Near Tpr = 1 Papay is poor even there.
+func GasZ(corr GasZCorrelation, Tpr, Ppr float64) (float64, error) {
+	if err := errors.Join(positive("Tpr", Tpr), positive("Ppr", Ppr)); err != nil {
+		return 0, err
+	}
+	if Tpr < 1 {
+		return 0, &InvalidInputError{Field: "Tpr", Value: Tpr, Constraint: ">= 1 for a gas Z correlation"}
+	}
+	switch corr {
+	case DranchukAbouKassem:
+		return dakZ(Tpr, Ppr)
+	case HallYarborough:
+		return hallYarboroughZ(Tpr, Ppr)
+	case Papay:
+		if Ppr > papayMaxPpr {
+			return 0, &InvalidInputError{Field: "Ppr", Value: Ppr, Constraint: fmt.Sprintf("<= %g for Papay", papayMaxPpr)}
+		}
+		return 1 - 3.52*Ppr/math.Pow(10, 0.9813*Tpr) + 0.274*Ppr*Ppr/math.Pow(10, 0.8157*Tpr), nil
+	}
+	return 0, fmt.Errorf("%w: unknown gas Z correlation %v", ErrInvalidInput, corr)
+}
+
+// GasZ returns the Z factor of a gas correlation at the reduced state of
+// cfg, with Tc and Pc taken as the pseudo-critical constants, for
+// comparison with the roots of cfg's EOS
+func (cfg EOSCfg) GasZ(corr GasZCorrelation) (float64, error) {
+	if err := errors.Join(positive("T", cfg.T), positive("P", cfg.P), positive("Tc", cfg.Tc), positive("Pc", cfg.Pc)); err != nil {
+		return 0, err
+	}
+	return GasZ(corr, cfg.Tr(), cfg.Pr())
+}
+
+// Dranchuk and Abou-Kassem, J. Can. Pet. Technol. 14 (1975) 34
+var dak = [11]float64{0.3265, -1.0700, -0.5339, 0.01569, -0.05165, 0.5475, -0.7361, 0.1844, 0.1056, 0.6134, 0.7210}
+
+// dakZ solves the DAK equation for the reduced density ρr = 0.27 Ppr/(Z Tpr)
+// by Newton's method
+func dakZ(tpr, ppr float64) (float64, error) {
+	t2, t3 := tpr*tpr, tpr*tpr*tpr
+	c1 := dak[0] + dak[1]/tpr + dak[2]/t3 + dak[3]/(t3*tpr) + dak[4]/(t3*t2)
+	c2 := dak[5] + dak[6]/tpr + dak[7]/t2
+	c3 := dak[8] * (dak[6]/tpr + dak[7]/t2)
+	c4 := dak[9] / t3
+	k := 0.27 * ppr / tpr
+
+	z := func(r float64) (float64, float64) {
+		r2 := r * r
+		e := math.Exp(-dak[10] * r2)
+		z := 1 + c1*r + c2*r2 - c3*r2*r2*r + c4*(1+dak[10]*r2)*r2*e
+		dz := c1 + 2*c2*r - 5*c3*r2*r2 + 2*c4*r*(1+dak[10]*r2-dak[10]*dak[10]*r2*r2)*e
+		return z, dz
+	}
+	r := k // Z = 1
+	for range gasZMaxIter {
+		zr, dz := z(r)
+		f := zr - k/r
+		next := r - f/(dz+k/(r*r))
+		if !(next > 0) {
+			next = 0.5 * r
+		}
+		if math.Abs(next-r) <= gasZTol*r {
+			zr, _ = z(next)
+			return zr, nil
+		}
+		r = next
+	}
+	return 0, fmt.Errorf("%w: Dranchuk-Abou-Kassem at Tpr = %g, Ppr = %g", ErrNoConvergence, tpr, ppr)
+}
+
+// hallYarboroughZ solves the Hall–Yarborough equation for the reduced
+// density y by Newton's method, Oil Gas J. 71 (1973) 82
+func hallYarboroughZ(tpr, ppr float64) (float64, error) {
+	t := 1 / tpr
+	a := 0.06125 * t * math.Exp(-1.2*(1-t)*(1-t))
+	b := 14.76*t - 9.76*t*t + 4.58*t*t*t
+	c := 90.7*t - 242.2*t*t + 42.4*t*t*t
+	d := 2.18 + 2.82*t
+
+	y := a * ppr // Z = 1
+	for range gasZMaxIter {
+		y2, y3, y4 := y*y, y*y*y, y*y*y*y
+		m := 1 - y
+		f := -a*ppr + (y+y2+y3-y4)/(m*m*m) - b*y2 + c*math.Pow(y, d)
+		df := (1+4*y+4*y2-4*y3+y4)/(m*m*m*m) - 2*b*y + c*d*math.Pow(y, d-1)
+		next := y - f/df
+		if !(next > 0 && next < 1) {
+			next = 0.5 * (y + math.Max(0, math.Min(1, next)))
+		}
+		if math.Abs(next-y) <= gasZTol*y {
+			return a * ppr / next, nil
+		}
+		y = next
+	}
+	return 0, fmt.Errorf("%w: Hall-Yarborough at Tpr = %g, Ppr = %g", ErrNoConvergence, tpr, ppr)
+}
+
+// Standard conditions of Bg: 60 °F and 1 atm
+const (
+	gasTsc = 288.7056 //K
+	gasPsc = 1.01325  //bar
+)
+
+// NaturalGas describes a natural gas by its specific gravity and acid gas
+// content
+type NaturalGas struct {
+	Gravity float64 //specific gravity, air = 1
+	H2S     float64 //mole fraction
+	CO2     float64 //mole fraction
+}
+
+// Sutton returns the hydrocarbon pseudo-critical temperature (K) and
+// pressure (bar) of a gas of specific gravity gravity, Sutton (1985)
+func Sutton(gravity float64) (Tpc, Ppc float64, err error) {
+	if err := positive("Gravity", gravity); err != nil {
+		return 0, 0, err
+	}
+	Tpc = (169.2 + 349.5*gravity - 74.0*gravity*gravity) / 1.8
+	Ppc = (756.8 - 131.0*gravity - 3.6*gravity*gravity) * psiaToBar
+	return Tpc, Ppc, nil
+}
+
+// WichertAziz corrects pseudo-critical constants (K, any pressure unit)
+// for the mole fractions of H₂S and CO₂ in a sour gas, Wichert and Aziz
+// (1972)
+func WichertAziz(Tpc, Ppc, H2S, CO2 float64) (float64, float64, error) {
+	var errs []error
+	errs = append(errs, positive("Tpc", Tpc), positive("Ppc", Ppc))
+	for _, f := range []struct {
+		name string
+		v    float64
+	}{{"H2S", H2S}, {"CO2", CO2}, {"H2S+CO2", H2S + CO2}} {
+		if !isFinite(f.v) || f.v < 0 || f.v > 1 {
+			errs = append(errs, &InvalidInputError{Field: f.name, Value: f.v, Constraint: "in [0, 1]"})
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return 0, 0, err
+	}
+	a, b := H2S+CO2, H2S
+	eps := (120*(math.Pow(a, 0.9)-math.Pow(a, 1.6)) + 15*(math.Sqrt(b)-math.Pow(b, 4))) / 1.8 //K
+	t := Tpc - eps
+	return t, Ppc * t / (Tpc + b*(1-b)*eps), nil
+}
+
+// PseudoCritical returns the pseudo-critical temperature (K) and pressure
+// (bar) of the gas: Sutton's, corrected by Wichert–Aziz when the gas
+// contains H₂S or CO₂
+func (g NaturalGas) PseudoCritical() (Tpc, Ppc float64, err error) {
+	Tpc, Ppc, err = Sutton(g.Gravity)
+	if err != nil {
+		return 0, 0, err
+	}
+	return WichertAziz(Tpc, Ppc, g.H2S, g.CO2)
+}
+
+// GasProperties are the volumetric properties of a natural gas from a Z
+// correlation
+type GasProperties struct {
+	T, P     float64 //K and bar
+	Tpc, Ppc float64 //pseudo-critical constants, K and bar
+	Tpr, Ppr float64
+	Z        float64
+	Bg       float64 //formation volume factor, reservoir per standard volume
+	Cg       float64 //isothermal compressibility 1/P - (∂Z/∂P)/Z, 1/bar
+}
+
+// Properties returns Z, Bg and cg of the gas at T (K) and P (bar). Bg is
+// referred to 60 °F and 1 atm; cg uses a central difference of Z in Ppr,
+// or a backward one at the end of Papay's range.
+func (g NaturalGas) Properties(T, P float64, corr GasZCorrelation) (GasProperties, error) {
+	if err := errors.Join(positive("T", T), positive("P", P)); err != nil {
+		return GasProperties{}, err
+	}
+	tpc, ppc, err := g.PseudoCritical()
+	if err != nil {
+		return GasProperties{}, err
+	}
+	p := GasProperties{T: T, P: P, Tpc: tpc, Ppc: ppc, Tpr: T / tpc, Ppr: P / ppc}
+	if p.Z, err = GasZ(corr, p.Tpr, p.Ppr); err != nil {
+		return GasProperties{}, err
+	}
+	h := 1e-4 * p.Ppr
+	hi, lo := p.Ppr+h, p.Ppr-h
+	if corr == Papay && hi > papayMaxPpr {
+		// backward difference at the end of Papay's range
+		hi = p.Ppr
+	}
+	up, err1 := GasZ(corr, p.Tpr, hi)
+	down, err2 := GasZ(corr, p.Tpr, lo)
+	if err := errors.Join(err1, err2); err != nil {
+		return GasProperties{}, err
+	}
+	p.Bg = gasPsc * p.Z * T / (gasTsc * P)
+	p.Cg = (1/p.Ppr - (up-down)/(hi-lo)/p.Z) / ppc
+	return p, nil
+}
+
+// EOSCfg returns a configuration of eos for the gas as a single
+// pseudo-component at the same state, to set the EOS Z beside the
+// correlations. R must be in bar-based units, e.g. 83.14 bar·cm³/(mol·K).
+func (p GasProperties) EOSCfg(eos EOSType, W, R float64) EOSCfg {
+	return EOSCfg{Type: eos, T: p.T, P: p.P, Tc: p.Tpc, Pc: p.Ppc, W: W, R: R}
+}
@@ -0,0 +1,97 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestGasZPapayRange(t *testing.T) {
+	if z, err := GasZ(Papay, 1.2, 15); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Papay at Ppr = 15: Z = %g, %v; want ErrInvalidInput", z, err)
+	}
+	for _, c := range []struct{ tpr, ppr float64 }{{1.2, 0.5}, {1.5, 1}, {2, 1}} {
+		papay, err := GasZ(Papay, c.tpr, c.ppr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dak, err := GasZ(DranchukAbouKassem, c.tpr, c.ppr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(papay-dak) > 0.02*dak {
+			t.Errorf("Tpr = %g, Ppr = %g: Papay %g, DAK %g", c.tpr, c.ppr, papay, dak)
+		}
+	}
+}
+
+func TestGasZStandingKatz(t *testing.T) {
+	// read from the Standing–Katz chart
+	for _, c := range []struct{ tpr, ppr, z float64 }{
+		{1.5, 2, 0.82},
+		{2, 3, 0.94},
+		{1.3, 2, 0.68},
+	} {
+		for _, corr := range []GasZCorrelation{DranchukAbouKassem, HallYarborough} {
+			z, err := GasZ(corr, c.tpr, c.ppr)
+			if err != nil {
+				t.Fatalf("%v: %v", corr, err)
+			}
+			if math.Abs(z-c.z) > 0.01 {
+				t.Errorf("%v at Tpr = %g, Ppr = %g: Z = %.4f, chart %g", corr, c.tpr, c.ppr, z, c.z)
+			}
+		}
+	}
+}
+
+func TestSuttonWichertAziz(t *testing.T) {
+	// γ = 0.7: Tpc = 377.59 °R, Ppc = 663.34 psia
+	tpc, ppc, err := Sutton(0.7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(tpc*1.8-377.59) > 0.01 || math.Abs(ppc/psiaToBar-663.34) > 0.01 {
+		t.Errorf("Sutton(0.7) = %g °R, %g psia; want 377.59, 663.34", tpc*1.8, ppc/psiaToBar)
+	}
+
+	// 10% H₂S and 5% CO₂: ε = 20.73 °R, so Tpc = 383.38 °R and Ppc =
+	// 604.4 psia correct to 362.65 °R and 568.9 psia
+	tpc, ppc, err = WichertAziz(383.38/1.8, 604.4, 0.1, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(tpc*1.8-362.65) > 0.01 || math.Abs(ppc-568.9) > 0.05 {
+		t.Errorf("WichertAziz = %g °R, %g psia; want 362.65, 568.9", tpc*1.8, ppc)
+	}
+	if _, _, err := WichertAziz(383.38/1.8, 604.4, 0.7, 0.5); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("H2S+CO2 > 1: %v, want ErrInvalidInput", err)
+	}
+
+	// a sweet gas is Sutton's unchanged
+	sutT, sutP, _ := Sutton(0.7)
+	gasT, gasP, err := NaturalGas{Gravity: 0.7}.PseudoCritical()
+	if err != nil || gasT != sutT || gasP != sutP {
+		t.Errorf("sweet gas: %g K, %g bar, %v; want %g, %g", gasT, gasP, err, sutT, sutP)
+	}
+}
+
+func TestGasPropertiesPapayBound(t *testing.T) {
+	// Ppr within a step of Papay's bound needs a one-sided difference;
+	// Papay's Z is a quadratic in Ppr, so dZ/dPpr is known exactly
+	g := NaturalGas{Gravity: 0.7}
+	tpc, ppc, err := g.PseudoCritical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ppr := range []float64{0.5, 1 - 1e-5, 1} {
+		p, err := g.Properties(1.5*tpc, ppr*ppc, Papay)
+		if err != nil {
+			t.Fatalf("Ppr = %g: %v", ppr, err)
+		}
+		dz := -3.52/math.Pow(10, 0.9813*p.Tpr) + 2*0.274*p.Ppr/math.Pow(10, 0.8157*p.Tpr)
+		want := (1/p.Ppr - dz/p.Z) / ppc
+		if math.Abs(p.Cg/want-1) > 1e-5 {
+			t.Errorf("Ppr = %g: cg = %g, want %g", ppr, p.Cg, want)
+		}
+	}
+}
@@ -2,8 +2,6 @@ package tui
 
 import (
 	"fmt"
-	"math"
-	"slices"
 	"strconv"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -19,6 +17,7 @@ const (
 	RK  eosOption = "Redlich-Kwong"
 	SRK eosOption = "Soave-Redlich-Kwong"
 	PR  eosOption = "Peng-Robinson"
+	LK  eosOption = "Lee-Kesler"
 	ALL eosOption = "All"
 )
 
@@ -30,20 +29,9 @@ const (
 	stateResult
 )
 
-type validator func(float64) error
-
-func positive(name string) validator {
-	return func(v float64) error {
-		if v <= 0 {
-			return fmt.Errorf("%s must be > 0", name)
-		}
-		return nil
-	}
-}
-
 type field struct {
-	name     string
-	validate validator
+	name string // label shown to the user
+	key  string // EOSCfg field the library validates
 }
 
 type model struct {
@@ -58,7 +46,7 @@ type model struct {
 }
 
 var eosChoices = []list.Item{
-	item(vdW), item(RK), item(SRK), item(PR), item(ALL),
+	item(vdW), item(RK), item(SRK), item(PR), item(LK), item(ALL),
 }
 
 type item string
@@ -151,13 +139,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.errMsg = fmt.Sprintf("Invalid number for %s: %q", fields[m.formIndex].name, inp)
 					return m, nil
 				}
+				m.parsed[fields[m.formIndex].name] = v
+
 				// Validate
-				if verr := fields[m.formIndex].validate(v); verr != nil {
-					m.errMsg = verr.Error()
+				if msg := m.fieldError(fields[m.formIndex]); msg != "" {
+					delete(m.parsed, fields[m.formIndex].name)
+					m.errMsg = msg
 					return m, nil
 				}
-
-				m.parsed[fields[m.formIndex].name] = v
 				m.errMsg = ""
 
 				// Next field or compute
@@ -240,72 +229,142 @@ func (m model) View() string {
 
 func (m model) requiredFields() []field {
 	base := []field{
-		{"T", positive("T")},
-		{"P", positive("P")},
-		{"Tc", positive("Tc")},
-		{"Pc", positive("Pc")},
-		{"R", positive("R")},
+		{"T", "T"},
+		{"P", "P"},
+		{"Tc", "Tc"},
+		{"Pc", "Pc"},
+		{"R", "R"},
 	}
-	if m.choice == SRK || m.choice == PR || m.choice == ALL {
-		// omega can be negative
-		base = append(base, field{"omega", func(v float64) error { return nil }})
+	if m.choice == SRK || m.choice == PR || m.choice == LK || m.choice == ALL {
+		base = append(base, field{"omega", "W"})
 	}
 	return base
 }
 
-func calculateb(eq cubiceos.EOSCfg, m model) float64 {
-	return eq.Type.Params().Omega * m.parsed["R"] * m.parsed["Tc"] / m.parsed["Pc"]
+// fieldError validates the form through the library and returns the
+// message for f, if any. Fields not entered yet take the blank default of 1.
+func (m model) fieldError(f field) string {
+	get := func(name string) float64 {
+		if v, ok := m.parsed[name]; ok {
+			return v
+		}
+		return 1
+	}
+	cfg := cubiceos.NewPRCfg(get("T"), get("P"), get("Tc"), get("Pc"), get("omega"), get("R"))
+	for _, e := range cubiceos.InputErrors(cfg.Validate()) {
+		if e.Field == f.key {
+			return e.Error()
+		}
+	}
+	return ""
 }
 
-func resultPrinter(eq cubiceos.EOSCfg, m model) string {
-	b := calculateb(eq, m)
-	const eps = 1e-9
+func resultPrinter(res cubiceos.Result, err error) string {
+	header := lipgloss.NewStyle().Bold(true).Foreground(colTitle)
+	label := lipgloss.NewStyle().Foreground(colLabel)
+	value := lipgloss.NewStyle().Foreground(colInput).Bold(true)
+	invalid := lipgloss.NewStyle().Foreground(colError).Italic(true)
 
-	roots, _ := cubiceos.CubicEOS(eq)
+	if err != nil {
+		return header.Render("Results\n") + "\n" + invalid.Render(err.Error())
+	}
+	out := header.Render(fmt.Sprintf("Results for %s EOS\n", res.EOS))
 
-	fs := make([]float64, 0, 3)
-	for _, v := range roots {
-		if imag(v) < eps {
-			fs = append(fs, real(v))
+	formatRoot := func(name string, v, z float64) string {
+		return label.Render(name+": ") + value.Render(fmt.Sprintf("%.4f", v)) + label.Render(fmt.Sprintf(" (Z = %.4f)", z))
+	}
+
+	switch res.Phase {
+	case cubiceos.PhaseNone:
+		out += "\n" + invalid.Render("No physically meaningful roots found")
+	case cubiceos.PhaseCritical:
+		out += "\n" + formatRoot("Critical volume", res.Volumes[0], res.Z[0])
+	case cubiceos.PhaseTwoRoot:
+		out += "\n" + formatRoot("Liquid root ("+res.Stability(0)+")", res.Volumes[0], res.Z[0])
+		if v, ok := res.Unstable(); ok {
+			out += "\n" + label.Render("Unstable root: ") + value.Render(fmt.Sprintf("%.4f", v))
 		}
+		n := res.NRoots - 1
+		out += "\n" + formatRoot("Vapour root ("+res.Stability(n)+")", res.Volumes[n], res.Z[n])
+		out += "\n" + label.Render("Stable phase: ") + value.Render(res.StablePhase().String())
+	default:
+		out += "\n" + formatRoot(fmt.Sprintf("Single phase (%s) molar volume", res.Phase), res.Volumes[0], res.Z[0])
 	}
-	slices.Sort(fs)
 
+	if i := res.Stable; i >= 0 {
+		out += "\n" + label.Render("Stable root H^R: ") + value.Render(fmt.Sprintf("%.4g", res.HR[i])) +
+			label.Render("  S^R: ") + value.Render(fmt.Sprintf("%.4g", res.SR[i]))
+	}
+
+	for _, rej := range res.Rejected[:res.NRejected] {
+		out += "\n" + invalid.Render(fmt.Sprintf("%.4f (invalid, %s)", rej.Root, rej.Reason))
+	}
+
+	return out
+}
+
+// liquidPrinter sets the liquid roots of cfgs beside the Rackett and
+// COSTALD liquid volumes. It is empty above Tc, where the correlations
+// do not apply.
+func liquidPrinter(cfgs ...cubiceos.EOSCfg) string {
 	header := lipgloss.NewStyle().Bold(true).Foreground(colTitle)
 	label := lipgloss.NewStyle().Foreground(colLabel)
 	value := lipgloss.NewStyle().Foreground(colInput).Bold(true)
-	invalid := lipgloss.NewStyle().Foreground(colError).Italic(true)
 
-	out := header.Render(fmt.Sprintf("Results for %s EOS\n", eq.Type.Name()))
-
-	formatRoot := func(name string, v float64) string {
-		if v <= 0 {
-			return label.Render(name+": ") + invalid.Render(fmt.Sprintf("%.4f (invalid, ≤0)", v))
+	if len(cfgs) == 0 || cfgs[0].T >= cfgs[0].Tc {
+		return ""
+	}
+	out := header.Render("Liquid volume correlations\n")
+	for i, cfg := range cfgs {
+		lv, err := cfg.LiquidVolumes()
+		if err != nil {
+			return ""
 		}
-		if v <= b {
-			return label.Render(name+": ") + invalid.Render(fmt.Sprintf("%.4f (invalid, <b=%.4f)", v, b))
+		if i == 0 {
+			out += "\n" + label.Render("Rackett: ") + value.Render(fmt.Sprintf("%.4f", lv.Rackett))
+			out += "\n" + label.Render("COSTALD: ") + value.Render(fmt.Sprintf("%.4f", lv.COSTALD))
 		}
-		return label.Render(name+": ") + value.Render(fmt.Sprintf("%.4f", v))
+		if lv.Cubic == 0 {
+			out += "\n" + label.Render(cfg.Type.Name()+": no liquid root")
+			continue
+		}
+		out += "\n" + label.Render(cfg.Type.Name()+": ") + value.Render(fmt.Sprintf("%.4f", lv.Cubic)) +
+			label.Render(fmt.Sprintf(" (%+.1f%% vs COSTALD)", lv.Deviation(lv.COSTALD)))
 	}
+	return out
+}
 
-	switch len(fs) {
-	case 1:
-		out += "\n" + formatRoot("Single phase molar volume", fs[0])
-	case 2:
-		out += "\n" + formatRoot("Root 1", fs[0])
-		out += "\n" + formatRoot("Root 2", fs[1])
-	case 3:
-		if math.Abs(fs[0]-fs[1]) < eps && math.Abs(fs[1]-fs[2]) < eps {
-			out += "\n" + formatRoot("Critical volume", fs[0])
-		} else {
-			out += "\n" + formatRoot("Liquid Vsat", fs[0])
-			out += "\n" + formatRoot("Unstable root", fs[1])
-			out += "\n" + formatRoot("Vapour Vsat", fs[2])
+// gasPrinter sets the gas Z correlations beside the stable Z of cfgs at
+// the same reduced state. It is empty below Tc, where they do not apply.
+func gasPrinter(cfgs ...cubiceos.EOSCfg) string {
+	header := lipgloss.NewStyle().Bold(true).Foreground(colTitle)
+	label := lipgloss.NewStyle().Foreground(colLabel)
+	value := lipgloss.NewStyle().Foreground(colInput).Bold(true)
+
+	if len(cfgs) == 0 || cfgs[0].T < cfgs[0].Tc {
+		return ""
+	}
+	out := header.Render("Gas Z correlations\n")
+	ref, err := cfgs[0].GasZ(cubiceos.GasZCorrelations[0])
+	if err != nil {
+		return ""
+	}
+	for _, corr := range cubiceos.GasZCorrelations {
+		// a correlation outside its range, such as Papay at high Ppr, is
+		// left out
+		if z, err := cfgs[0].GasZ(corr); err == nil {
+			out += "\n" + label.Render(corr.String()+": ") + value.Render(fmt.Sprintf("%.4f", z))
 		}
-	default:
-		out += "\n" + invalid.Render("No real roots found")
 	}
-
+	for _, cfg := range cfgs {
+		res, err := cubiceos.Solve(cfg)
+		if err != nil || res.Stable < 0 {
+			continue
+		}
+		z := res.Z[res.Stable]
+		out += "\n" + label.Render(cfg.Type.Name()+": ") + value.Render(fmt.Sprintf("%.4f", z)) +
+			label.Render(fmt.Sprintf(" (%+.1f%% vs %s)", 100*(z-ref)/ref, cubiceos.GasZCorrelations[0]))
+	}
 	return out
 }
 
@@ -314,14 +373,20 @@ func (m model) compute() string {
 	rkCfg := cubiceos.NewRKCfg(m.parsed["T"], m.parsed["P"], m.parsed["Tc"], m.parsed["Pc"], m.parsed["R"])
 	srkCfg := cubiceos.NewSRKCfg(m.parsed["T"], m.parsed["P"], m.parsed["Tc"], m.parsed["Pc"], m.parsed["omega"], m.parsed["R"])
 	prCfg := cubiceos.NewPRCfg(m.parsed["T"], m.parsed["P"], m.parsed["Tc"], m.parsed["Pc"], m.parsed["omega"], m.parsed["R"])
+	lkResult := func() (cubiceos.Result, error) {
+		return cubiceos.LeeKesler(m.parsed["T"], m.parsed["P"], m.parsed["Tc"], m.parsed["Pc"], m.parsed["omega"], m.parsed["R"])
+	}
 
-	// If the user selected ALL, render a 2x2 grid
+	// If the user selected ALL, render a grid
 	if m.choice == ALL {
 		// Compute each result box
-		resVdW := resultPrinter(vCfg, m)
-		resRK := resultPrinter(rkCfg, m)
-		resSRK := resultPrinter(srkCfg, m)
-		resPR := resultPrinter(prCfg, m)
+		resVdW := resultPrinter(cubiceos.Solve(vCfg))
+		resRK := resultPrinter(cubiceos.Solve(rkCfg))
+		resSRK := resultPrinter(cubiceos.Solve(srkCfg))
+		resPR := resultPrinter(cubiceos.Solve(prCfg))
+		resLK := resultPrinter(lkResult())
+		resLiquid := liquidPrinter(srkCfg, prCfg)
+		resGas := gasPrinter(srkCfg, prCfg)
 
 		// Small box style wrapper
 		boxStyle := lipgloss.NewStyle().
@@ -334,10 +399,18 @@ func (m model) compute() string {
 		boxRK := boxStyle.Render(resRK)
 		boxSRK := boxStyle.Render(resSRK)
 		boxPR := boxStyle.Render(resPR)
+		boxLK := boxStyle.Render(resLK)
 
-		// Build two vertical columns (each column contains two boxes stacked)
-		leftCol := lipgloss.JoinVertical(lipgloss.Left, boxVdW, boxSRK)
+		// Build two vertical columns; Lee-Kesler sits under the cubics
+		// as the reference, the liquid correlations under PR below Tc and
+		// the gas Z correlations above it
+		leftCol := lipgloss.JoinVertical(lipgloss.Left, boxVdW, boxSRK, boxLK)
 		rightCol := lipgloss.JoinVertical(lipgloss.Left, boxRK, boxPR)
+		for _, extra := range []string{resLiquid, resGas} {
+			if extra != "" {
+				rightCol = lipgloss.JoinVertical(lipgloss.Left, rightCol, boxStyle.Render(extra))
+			}
+		}
 
 		// Put columns side-by-side with a small gap
 		gap := lipgloss.NewStyle().PaddingLeft(2)
@@ -349,19 +422,36 @@ func (m model) compute() string {
 	// Single EOS mode
 	switch m.choice {
 	case vdW:
-		return resultPrinter(vCfg, m)
+		return resultPrinter(cubiceos.Solve(vCfg))
 	case RK:
-		return resultPrinter(rkCfg, m)
+		return resultPrinter(cubiceos.Solve(rkCfg))
 	case SRK:
-		return resultPrinter(srkCfg, m)
+		return joinResults(resultPrinter(cubiceos.Solve(srkCfg)), liquidPrinter(srkCfg), gasPrinter(srkCfg))
 	case PR:
-		return resultPrinter(prCfg, m)
+		return joinResults(resultPrinter(cubiceos.Solve(prCfg)), liquidPrinter(prCfg), gasPrinter(prCfg))
+	case LK:
+		return resultPrinter(lkResult())
 	default:
 		return "something went wrong"
 	}
 
 }
 
+// joinResults stacks the non-empty result blocks with a blank line between
+func joinResults(blocks ...string) string {
+	out := ""
+	for _, b := range blocks {
+		if b == "" {
+			continue
+		}
+		if out != "" {
+			out += "\n\n"
+		}
+		out += b
+	}
+	return out
+}
+
 func Run() error {
 	_, err := tea.NewProgram(
 		initialModel(),
@@ -3,11 +3,32 @@ package pages
 // EOSResult represents interpreted cubic EOS roots for display.
 type EOSResult struct {
 	Name           string
-	Classification string // single-phase | two-phase | critical | none
+	Classification string // cubiceos.Phase name, or error
 	Liquid         *float64
 	Unstable       *float64
 	Vapor          *float64
-	A              float64 // a(T)
-	B              float64 // b
-	Error          string  // error message from solver (if any)
+	Stable         string        // phase of the stable root when both liquid and vapour roots exist
+	Z              *float64      // Z of the stable root
+	HR             *float64      // residual enthalpy of the stable root
+	SR             *float64      // residual entropy of the stable root
+	A              float64       // a(T), 0 for non-cubic models
+	B              float64       // b, 0 for non-cubic models
+	Rackett        *float64      // Rackett saturated liquid volume, below Tc only
+	COSTALD        *float64      // COSTALD compressed liquid volume, below Tc only
+	GasZ           []Correlation // gas Z-factor correlations, above Tc only
+	Rejected       []string      // roots of the cubic that are not physical volumes
+	Error          string        // error message from solver (if any)
+}
+
+// Correlation is the value of a named correlation shown beside an EOS
+// result.
+type Correlation struct {
+	Name  string
+	Value float64
+}
+
+// FieldError is a validation message for a single form field.
+type FieldError struct {
+	Field   string // form field name, e.g. "Tc"
+	Message string
 }
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"math"
 	"net"
 	"net/http"
 	"net/url"
@@ -13,7 +12,7 @@ import (
 	"os/exec"
 	"os/signal"
 	"runtime"
-	"sort"
+	"slices"
 	"strconv"
 	"syscall"
 	"time"
@@ -39,47 +38,28 @@ func newSrvMux() *http.ServeMux {
 			return
 		}
 
-		parseFloat := func(name string, required bool) (float64, error) {
+		var fieldErrs []pages.FieldError
+		parseFloat := func(name string, required bool) float64 {
 			v := r.FormValue(name)
 			if v == "" {
 				if required {
-					return 0, fmt.Errorf("%s required", name)
+					fieldErrs = append(fieldErrs, pages.FieldError{Field: name, Message: "required"})
 				}
-				return 0, nil
+				return 0
 			}
 			f, err := strconv.ParseFloat(v, 64)
 			if err != nil {
-				return 0, fmt.Errorf("invalid %s", name)
+				fieldErrs = append(fieldErrs, pages.FieldError{Field: name, Message: fmt.Sprintf("%q is not a number", v)})
 			}
-			return f, nil
+			return f
 		}
 
-		T, err := parseFloat("T", true)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		P, err := parseFloat("P", true)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		Tc, err := parseFloat("Tc", true)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		Pc, err := parseFloat("Pc", true)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		R, err := parseFloat("R", true)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		omega, _ := parseFloat("omega", false)
+		T := parseFloat("T", true)
+		P := parseFloat("P", true)
+		Tc := parseFloat("Tc", true)
+		Pc := parseFloat("Pc", true)
+		R := parseFloat("R", true)
+		omega := parseFloat("omega", false)
 		withAdv := r.FormValue("with_advanced") != ""
 
 		// Build configurations
@@ -88,76 +68,86 @@ func newSrvMux() *http.ServeMux {
 		srkCfg := cubiceos.NewSRKCfg(T, P, Tc, Pc, omega, R)
 		prCfg := cubiceos.NewPRCfg(T, P, Tc, Pc, omega, R)
 
-		calcAB := func(cfg cubiceos.EOSCfg) (float64, float64) {
-			tr := cfg.T / cfg.Tc
-			a := cfg.Type.Params().Psi * cfg.Type.Alpha(tr, cfg.W) * cfg.R * cfg.R * cfg.Tc * cfg.Tc / cfg.Pc
-			b := cfg.Type.Params().Omega * cfg.R * cfg.Tc / cfg.Pc
-			return a, b
+		// Report every bad field at once rather than failing on the first.
+		// Fields that failed to parse are not re-reported by the library.
+		for _, e := range cubiceos.InputErrors(prCfg.Validate()) {
+			name := e.Field
+			if name == "W" {
+				name = "omega"
+			}
+			if !slices.ContainsFunc(fieldErrs, func(fe pages.FieldError) bool { return fe.Field == name }) {
+				fieldErrs = append(fieldErrs, pages.FieldError{Field: name, Message: "must be " + e.Constraint})
+			}
 		}
-
-		collect := func(name string, cfg cubiceos.EOSCfg, include bool) *pages.EOSResult {
-			if !include {
-				return nil
+		if len(fieldErrs) > 0 {
+			// htmx only swaps 2xx responses, so the errors are sent as a
+			// normal fragment in place of the results
+			if err := pages.InputErrors(fieldErrs).Render(r.Context(), w); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
 			}
-			a, b := calcAB(cfg)
-			roots, err := cubiceos.CubicEOS(cfg)
+			return
+		}
+
+		collect := func(name string, res cubiceos.Result, err error) pages.EOSResult {
 			if err != nil {
-				return &pages.EOSResult{Name: name, Classification: "error", Error: err.Error(), A: a, B: b}
+				return pages.EOSResult{Name: name, Classification: "error", Error: err.Error()}
 			}
-			const eps = 1e-9
-			positives := make([]float64, 0, 3)
-			for _, rt := range roots {
-				if math.Abs(imag(rt)) < eps {
-					rv := real(rt)
-					if rv > 0 {
-						positives = append(positives, rv)
-					}
-				}
+			out := pages.EOSResult{
+				Name:           res.EOS,
+				Classification: res.Phase.String(),
+				A:              res.A,
+				B:              res.B,
 			}
-			sort.Float64s(positives)
-			res := &pages.EOSResult{Name: name, A: a, B: b}
-			switch len(positives) {
-			case 0:
-				res.Classification = "none"
-			case 1:
-				res.Classification = "single-phase"
-				res.Vapor = &positives[0]
-			case 2:
-				// treat smaller as liquid, larger as vapor (approx)
-				res.Classification = "two-phase"
-				res.Liquid = &positives[0]
-				res.Vapor = &positives[1]
-			case 3:
-				// Check for critical (all nearly equal)
-				if math.Abs(positives[0]-positives[1]) < 1e-6 && math.Abs(positives[1]-positives[2]) < 1e-6 {
-					res.Classification = "critical"
-					res.Vapor = &positives[0]
-				} else {
-					res.Classification = "two-phase"
-					res.Liquid = &positives[0]
-					res.Unstable = &positives[1]
-					res.Vapor = &positives[2]
-				}
+			if res.Phase == cubiceos.PhaseTwoRoot {
+				out.Stable = res.StablePhase().String()
+			}
+			if i := res.Stable; i >= 0 {
+				out.Z, out.HR, out.SR = &res.Z[i], &res.HR[i], &res.SR[i]
+			}
+			if v, ok := res.Liquid(); ok {
+				out.Liquid = &v
+			}
+			if v, ok := res.Unstable(); ok {
+				out.Unstable = &v
+			}
+			if v, ok := res.Vapour(); ok {
+				out.Vapor = &v
 			}
-			return res
+			for _, rej := range res.Rejected[:res.NRejected] {
+				out.Rejected = append(out.Rejected, fmt.Sprintf("%.6g (%s)", rej.Root, rej.Reason))
+			}
+			return out
 		}
 
-		results := make([]pages.EOSResult, 0, 4)
-		if r := collect("van der Waals", vdWCfg, true); r != nil {
-			results = append(results, *r)
-		}
-		if r := collect("Redlich-Kwong", rkCfg, true); r != nil {
-			results = append(results, *r)
+		solve := func(cfg cubiceos.EOSCfg) pages.EOSResult {
+			res, err := cubiceos.Solve(cfg)
+			return collect(cfg.Type.Name(), res, err)
 		}
-		if withAdv {
-			if r := collect("Soave-Redlich-Kwong", srkCfg, true); r != nil {
-				results = append(results, *r)
+
+		// withCorrelations sets the Rackett and COSTALD volumes beside the
+		// liquid root below Tc, and the gas Z correlations beside Z above
+		// it; they need ω, so only SRK and PR get them
+		withCorrelations := func(cfg cubiceos.EOSCfg) pages.EOSResult {
+			out := solve(cfg)
+			if lv, err := cfg.LiquidVolumes(); err == nil {
+				out.Rackett, out.COSTALD = &lv.Rackett, &lv.COSTALD
 			}
-			if r := collect("Peng-Robinson", prCfg, true); r != nil {
-				results = append(results, *r)
+			for _, corr := range cubiceos.GasZCorrelations {
+				if z, err := cfg.GasZ(corr); err == nil {
+					out.GasZ = append(out.GasZ, pages.Correlation{Name: corr.String(), Value: z})
+				}
 			}
+			return out
 		}
 
+		results := make([]pages.EOSResult, 0, 5)
+		results = append(results, solve(vdWCfg), solve(rkCfg))
+		if withAdv {
+			results = append(results, withCorrelations(srkCfg), withCorrelations(prCfg))
+			// Lee-Kesler is the reference the cubics are compared against
+			lk, err := cubiceos.LeeKesler(T, P, Tc, Pc, omega, R)
+			results = append(results, collect("Lee-Kesler", lk, err))
+		}
 		if err := pages.ResultsPage(results).Render(r.Context(), w); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
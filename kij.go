@@ -0,0 +1,364 @@
+package cubiceos
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// VLEPoint is one measured binary vapour–liquid equilibrium point
+type VLEPoint struct {
+	T, P float64
+	X1   float64 //liquid mole fraction of the first component
+	Y1   float64 //vapour mole fraction of the first component, NaN if not measured
+}
+
+// ReadVLEData parses binary T–P–x–y data from CSV. The header row names
+// the columns T, P, x and y, in any order and case; y is optional and a
+// blank y cell marks an unmeasured vapour composition. Lines starting with
+// '#' are comments. Units are those of the mixture the data are fitted
+// with.
+func ReadVLEData(r io.Reader) ([]VLEPoint, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	col := map[string]int{"t": -1, "p": -1, "x": -1, "y": -1}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if _, ok := col[h]; ok {
+			col[h] = i
+		}
+	}
+	for _, c := range []string{"t", "p", "x"} {
+		if col[c] < 0 {
+			return nil, fmt.Errorf("header has no %s column", strings.ToUpper(c))
+		}
+	}
+
+	var data []VLEPoint
+	var errs []error
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		cell := func(c string) (float64, error) {
+			i := col[c]
+			if i < 0 || i >= len(rec) || strings.TrimSpace(rec[i]) == "" {
+				return math.NaN(), nil
+			}
+			return strconv.ParseFloat(strings.TrimSpace(rec[i]), 64)
+		}
+		T, err1 := cell("t")
+		P, err2 := cell("p")
+		x, err3 := cell("x")
+		y, err4 := cell("y")
+		if err := errors.Join(err1, err2, err3, err4); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		pt := VLEPoint{T: T, P: P, X1: x, Y1: y}
+		if err := pt.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		data = append(data, pt)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (p VLEPoint) validate() error {
+	var errs []error
+	errs = append(errs, positive("T", p.T), positive("P", p.P))
+	if !(p.X1 >= 0 && p.X1 <= 1) {
+		errs = append(errs, &InvalidInputError{Field: "x", Value: p.X1, Constraint: "in [0, 1]"})
+	}
+	if !math.IsNaN(p.Y1) && !(p.Y1 >= 0 && p.Y1 <= 1) {
+		errs = append(errs, &InvalidInputError{Field: "y", Value: p.Y1, Constraint: "in [0, 1]"})
+	}
+	return errors.Join(errs...)
+}
+
+// KijPair is the classical binary interaction parameter k_ij = K + KT T
+// of components I and J
+type KijPair struct {
+	I, J  string
+	K, KT float64
+}
+
+// ReadKij parses a binary interaction parameter file. Each non-blank line
+// that does not start with '#' is one pair,
+//
+//	i  j  K  [KT]
+//
+// with component names containing no whitespace.
+func ReadKij(r io.Reader) ([]KijPair, error) {
+	var pairs []KijPair
+	var errs []error
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		f := strings.Fields(text)
+		if len(f) != 3 && len(f) != 4 {
+			errs = append(errs, fmt.Errorf("line %d: want i, j, K and optionally KT, got %d fields", line, len(f)))
+			continue
+		}
+		p := KijPair{I: f[0], J: f[1]}
+		if p.I == p.J {
+			errs = append(errs, fmt.Errorf("line %d: pair of %s with itself", line, p.I))
+			continue
+		}
+		var err error
+		if p.K, err = strconv.ParseFloat(f[2], 64); err == nil && len(f) == 4 {
+			p.KT, err = strconv.ParseFloat(f[3], 64)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		pairs = append(pairs, p)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// WriteKij writes pairs in the format read by ReadKij
+func WriteKij(w io.Writer, pairs []KijPair) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# i j K KT   (k_ij = K + KT T)")
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	for _, p := range pairs {
+		fmt.Fprintf(bw, "%s %s %s %s\n", p.I, p.J, f(p.K), f(p.KT))
+	}
+	return bw.Flush()
+}
+
+// NewClassical builds the classical mixing rule for the named components
+// from pairs. Pairs naming other components are ignored; missing pairs
+// have k_ij = 0.
+func NewClassical(names []string, pairs []KijPair) (Classical, error) {
+	index := make(map[string]int, len(names))
+	for i, n := range names {
+		if _, dup := index[n]; dup {
+			return Classical{}, fmt.Errorf("duplicate component %q", n)
+		}
+		index[n] = i
+	}
+	n := len(names)
+	r := Classical{K: make([][]float64, n), KT: make([][]float64, n)}
+	for i := range n {
+		r.K[i], r.KT[i] = make([]float64, n), make([]float64, n)
+	}
+	seen := make(map[[2]int]bool)
+	for _, p := range pairs {
+		i, iok := index[p.I]
+		j, jok := index[p.J]
+		if !iok || !jok {
+			continue
+		}
+		key := [2]int{min(i, j), max(i, j)}
+		if seen[key] {
+			return Classical{}, fmt.Errorf("pair %s-%s given twice", p.I, p.J)
+		}
+		seen[key] = true
+		r.K[i][j], r.K[j][i] = p.K, p.K
+		r.KT[i][j], r.KT[j][i] = p.KT, p.KT
+	}
+	return r, nil
+}
+
+// KijObjective selects the deviations FitKij minimises
+type KijObjective int
+
+const (
+	// KijBubbleP minimises relative bubble-pressure deviations
+	KijBubbleP KijObjective = iota
+	// KijVapour minimises vapour mole fraction deviations at the bubble
+	// point; points without a measured y are skipped
+	KijVapour
+)
+
+// KijFitOptions controls FitKij
+type KijFitOptions struct {
+	Objective KijObjective
+	Linear    bool    //also fit the temperature slope KT
+	K0        float64 //initial K
+}
+
+// KijResidual compares the fit with one data point
+type KijResidual struct {
+	VLEPoint
+	PCalc  float64 //bubble pressure at T and X1
+	Y1Calc float64 //vapour mole fraction at the bubble point
+	DP     float64 //100 (PCalc - P)/P
+	DY     float64 //Y1Calc - Y1, NaN if Y1 is not measured
+}
+
+// KijFit is the result of FitKij
+type KijFit struct {
+	Names             [2]string
+	K, KT             float64
+	StdErrK, StdErrKT float64    //standard errors, NaN if undefined; StdErrKT is 0 unless Linear
+	Pressure          Deviations //of DP, in %
+	Vapour            Deviations //of DY
+	Residuals         []KijResidual
+	Iterations        int
+}
+
+// Pair returns the fitted parameters in the form read by NewClassical
+func (f KijFit) Pair() KijPair {
+	return KijPair{I: f.Names[0], J: f.Names[1], K: f.K, KT: f.KT}
+}
+
+// WriteResiduals writes the residuals as CSV, for plotting
+func (f KijFit) WriteResiduals(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	g := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	cw.Write([]string{"T", "P", "x", "y", "P_calc", "y_calc", "dP_pct", "dy"})
+	for _, r := range f.Residuals {
+		cw.Write([]string{g(r.T), g(r.P), g(r.X1), g(r.Y1), g(r.PCalc), g(r.Y1Calc), g(r.DP), g(r.DY)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// FitKij regresses the classical k_ij of a binary, constant or linear in
+// T, for eos against measured bubble points by Levenberg–Marquardt. Each
+// data point is a φ–φ bubble-pressure calculation at its T and x. The
+// temperature slope is fitted scaled by the mean data temperature so both
+// parameters are of similar size.
+func FitKij(eos EOSType, comps []Component, R float64, data []VLEPoint, opt KijFitOptions) (KijFit, error) {
+	if len(comps) != 2 {
+		return KijFit{}, &InvalidInputError{Field: "components", Value: float64(len(comps)), Constraint: "2 for a binary fit"}
+	}
+	if len(data) == 0 {
+		return KijFit{}, fmt.Errorf("%w: no data to fit", ErrInvalidInput)
+	}
+	var errs []error
+	tRef := 0.0
+	for i, d := range data {
+		if err := d.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("data[%d]: %w", i, err))
+		}
+		tRef += d.T / float64(len(data))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return KijFit{}, err
+	}
+	if opt.Objective == KijVapour && deviations(vapourCol(data)).N == 0 {
+		return KijFit{}, fmt.Errorf("%w: no data point has a measured y to fit", ErrInvalidInput)
+	}
+
+	// mixture builds the mixture at fitted parameters p = (K, KT·tRef)
+	mixture := func(p []float64) (*Mixture, error) {
+		rule := Classical{K: [][]float64{{0, p[0]}, {p[0], 0}}}
+		if len(p) == 2 {
+			kt := p[1] / tRef
+			rule.KT = [][]float64{{0, kt}, {kt, 0}}
+		}
+		return NewMixture(eos, comps, rule, R)
+	}
+	// each point restarts from its last converged bubble point
+	last := make([]*VLEResult, len(data))
+	bubbles := func(p []float64) ([]VLEResult, error) {
+		m, err := mixture(p)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]VLEResult, len(data))
+		for i, d := range data {
+			x := []float64{d.X1, 1 - d.X1}
+			var res VLEResult
+			if last[i] != nil {
+				res, err = m.bubble(d.T, last[i].P, x, last[i].Y, true)
+			}
+			if last[i] == nil || err != nil {
+				res, err = m.BubbleP(d.T, x)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("data[%d]: %w", i, err)
+			}
+			out[i], last[i] = res, &res
+		}
+		return out, nil
+	}
+	residuals := func(p []float64) ([]float64, error) {
+		res, err := bubbles(p)
+		if err != nil {
+			return nil, err
+		}
+		var r []float64
+		for i, d := range data {
+			switch opt.Objective {
+			case KijVapour:
+				if !math.IsNaN(d.Y1) {
+					r = append(r, res[i].Y[0]-d.Y1)
+				}
+			default:
+				r = append(r, (res[i].P-d.P)/d.P)
+			}
+		}
+		return r, nil
+	}
+
+	p0 := []float64{opt.K0}
+	if opt.Linear {
+		p0 = append(p0, 0)
+	}
+	lm, err := levenbergMarquardt(residuals, p0)
+	if err != nil {
+		return KijFit{}, err
+	}
+	res, err := bubbles(lm.p)
+	if err != nil {
+		return KijFit{}, err
+	}
+
+	fit := KijFit{Names: [2]string{comps[0].Name, comps[1].Name}, K: lm.p[0], Iterations: lm.iterations}
+	se := lm.stdErr()
+	fit.StdErrK = se[0]
+	if opt.Linear {
+		fit.KT, fit.StdErrKT = lm.p[1]/tRef, se[1]/tRef
+	}
+	dp, dy := make([]float64, len(data)), make([]float64, len(data))
+	for i, d := range data {
+		dp[i] = 100 * (res[i].P - d.P) / d.P
+		dy[i] = res[i].Y[0] - d.Y1
+		fit.Residuals = append(fit.Residuals, KijResidual{VLEPoint: d, PCalc: res[i].P, Y1Calc: res[i].Y[0], DP: dp[i], DY: dy[i]})
+	}
+	fit.Pressure, fit.Vapour = deviations(dp), deviations(dy)
+	return fit, nil
+}
+
+func vapourCol(data []VLEPoint) []float64 {
+	y := make([]float64, len(data))
+	for i, d := range data {
+		y[i] = d.Y1
+	}
+	return y
+}
@@ -0,0 +1,74 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFitKijRoundTrip(t *testing.T) {
+	comps := []Component{
+		{Name: "methane", Tc: 190.6, Pc: 46.0, W: 0.012},
+		{Name: "propane", Tc: 369.8, Pc: 42.48, W: 0.152},
+	}
+	for _, tc := range []struct {
+		name  string
+		K, KT float64
+		opt   KijFitOptions
+	}{
+		{"constant", 0.03, 0, KijFitOptions{}},
+		{"linear", 0.05, -1e-4, KijFitOptions{Linear: true}},
+		{"vapour", 0.03, 0, KijFitOptions{Objective: KijVapour}},
+	} {
+		m, err := NewMixture(PR{}, comps, Classical{
+			K:  [][]float64{{0, tc.K}, {tc.K, 0}},
+			KT: [][]float64{{0, tc.KT}, {tc.KT, 0}},
+		}, barCm3R)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// synthetic bubble points generated with the known k_ij
+		var data []VLEPoint
+		for _, T := range []float64{230, 250, 270} {
+			for _, x1 := range []float64{0.05, 0.15, 0.3} {
+				res, err := m.BubbleP(T, []float64{x1, 1 - x1})
+				if err != nil {
+					t.Fatalf("%s: T = %g, x1 = %g: %v", tc.name, T, x1, err)
+				}
+				data = append(data, VLEPoint{T: T, P: res.P, X1: x1, Y1: res.Y[0]})
+			}
+		}
+		fit, err := FitKij(PR{}, comps, barCm3R, data, tc.opt)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if math.Abs(fit.K-tc.K) > 1e-6 || math.Abs(fit.KT-tc.KT) > 1e-8 {
+			t.Errorf("%s: fitted K = %g, KT = %g; want %g, %g", tc.name, fit.K, fit.KT, tc.K, tc.KT)
+		}
+		if fit.Pressure.Max > 1e-5 {
+			t.Errorf("%s: largest pressure deviation %g%%", tc.name, fit.Pressure.Max)
+		}
+	}
+}
+
+func TestFitKijInvalid(t *testing.T) {
+	comps := []Component{
+		{Name: "methane", Tc: 190.6, Pc: 46.0, W: 0.012},
+		{Name: "propane", Tc: 369.8, Pc: 42.48, W: 0.152},
+	}
+	noY := []VLEPoint{{T: 250, P: 30, X1: 0.2, Y1: math.NaN()}}
+	for name, tc := range map[string]struct {
+		comps []Component
+		data  []VLEPoint
+		opt   KijFitOptions
+	}{
+		"one component": {comps[:1], noY, KijFitOptions{}},
+		"no data":       {comps, nil, KijFitOptions{}},
+		"no y":          {comps, noY, KijFitOptions{Objective: KijVapour}},
+		"bad x":         {comps, []VLEPoint{{T: 250, P: 30, X1: 1.5, Y1: math.NaN()}}, KijFitOptions{}},
+	} {
+		if _, err := FitKij(PR{}, tc.comps, barCm3R, tc.data, tc.opt); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: %v, want ErrInvalidInput", name, err)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+)
+
+// lkFluid holds the BWR-type constants of one Lee–Kesler reference fluid
+type lkFluid struct {
+	b1, b2, b3, b4 float64
+	c1, c2, c3, c4 float64
+	d1, d2         float64
+	beta, gamma    float64
+	zc             float64
+}
+
+// Lee & Kesler, AIChE J. 21 (1975) 510
+var (
+	lkSimple = lkFluid{
+		b1: 0.1181193, b2: 0.265728, b3: 0.154790, b4: 0.030323,
+		c1: 0.0236744, c2: 0.0186984, c3: 0.0, c4: 0.042724,
+		d1: 0.155488e-4, d2: 0.623689e-4,
+		beta: 0.65392, gamma: 0.060167,
+		zc: 0.2901,
+	}
+	lkReference = lkFluid{
+		b1: 0.2026579, b2: 0.331511, b3: 0.027655, b4: 0.203488,
+		c1: 0.0313385, c2: 0.0503618, c3: 0.016901, c4: 0.041577,
+		d1: 0.48736e-4, d2: 0.0740336e-4,
+		beta: 1.226, gamma: 0.03754,
+		zc: 0.2635,
+	}
+)
+
+// lkOmegaRef is the acentric factor of the reference fluid, n-octane
+const lkOmegaRef = 0.3978
+
+// lkCoeffs are the temperature-dependent virial-like coefficients at Tr
+type lkCoeffs struct {
+	B, C, D float64
+	e       float64 //c4/Tr³
+}
+
+func (f lkFluid) coeffs(tr float64) lkCoeffs {
+	return lkCoeffs{
+		B: f.b1 - f.b2/tr - f.b3/(tr*tr) - f.b4/(tr*tr*tr),
+		C: f.c1 - f.c2/tr + f.c3/(tr*tr*tr),
+		D: f.d1 + f.d2/tr,
+		e: f.c4 / (tr * tr * tr),
+	}
+}
+
+// z returns Z and dZ/dVr at pseudo-reduced volume vr
+func (f lkFluid) z(k lkCoeffs, vr float64) (z, dz float64) {
+	v2 := vr * vr
+	ex := math.Exp(-f.gamma / v2)
+	g := f.beta/v2 + f.gamma/(v2*v2)
+	z = 1 + k.B/vr + k.C/v2 + k.D/(v2*v2*vr) + k.e*g*ex
+	dg := -2*f.beta/(v2*vr) - 4*f.gamma/(v2*v2*vr)
+	dz = -k.B/v2 - 2*k.C/(v2*vr) - 5*k.D/(v2*v2*v2) + k.e*ex*(dg+g*2*f.gamma/(v2*vr))
+	return z, dz
+}
+
+// volume solves Pr = Tr Z/Vr for the liquid or vapour root by the same
+// safeguarded Newton scheme as density. ok is false if the requested
+// branch was not found.
+func (f lkFluid) volume(k lkCoeffs, tr, pr float64, liquid bool) (vr float64, ok bool) {
+	vr = tr / pr
+	if liquid {
+		vr = 0.05 * f.zc
+	}
+	forced := 0
+	for range densityMaxIter {
+		z, dz := f.z(k, vr)
+		p := tr * z / vr
+		dp := tr * (dz*vr - z) / (vr * vr)
+
+		var next float64
+		if dp < 0 {
+			next = vr - (p-pr)/dp
+		}
+		if dp >= 0 || !(next > 0) || !isFinite(next) {
+			if forced++; forced > densityMaxBisect {
+				return 0, false
+			}
+			if liquid {
+				next = 0.5 * vr
+			} else {
+				next = 2 * vr
+			}
+		} else {
+			forced = 0
+		}
+		if math.Abs(next-vr) <= densityTol*vr {
+			// the liquid root lies below the critical volume, the vapour
+			// root above it (Vr,c = Zc)
+			return next, dp < 0 && (tr >= 1 || liquid == (next < f.zc))
+		}
+		vr = next
+	}
+	return 0, false
+}
+
+// residual returns ln φ, H^R/(R Tc) and S^R/R of the fluid at tr and vr
+func (f lkFluid) residual(k lkCoeffs, tr, vr float64) (lnPhi, hr, sr float64) {
+	z, _ := f.z(k, vr)
+	v2 := vr * vr
+	v5 := v2 * v2 * vr
+	ex := math.Exp(-f.gamma / v2)
+	tr2, tr3 := tr*tr, tr*tr*tr
+	e := f.c4 / (2 * tr3 * f.gamma) * (f.beta + 1 - (f.beta+1+f.gamma/v2)*ex)
+
+	lnPhi = z - 1 - math.Log(z) + k.B/vr + k.C/(2*v2) + k.D/(5*v5) + e
+	hr = tr * (z - 1 - (f.b2+2*f.b3/tr+3*f.b4/tr2)/(tr*vr) - (f.c2-3*f.c3/tr2)/(2*tr*v2) + f.d2/(5*tr*v5) + 3*e)
+	sr = math.Log(z) - (f.b1+f.b3/tr2+2*f.b4/tr3)/vr - (f.c1-2*f.c3/tr3)/(2*v2) - f.d1/(5*v5) + 2*e
+	return lnPhi, hr, sr
+}
+
+// lkSaturationPr is the Lee–Kesler vapour pressure correlation
+func lkSaturationPr(tr, w float64) float64 {
+	lt := math.Log(tr)
+	t6 := math.Pow(tr, 6)
+	f0 := 5.92714 - 6.09648/tr - 1.28862*lt + 0.169347*t6
+	f1 := 15.2518 - 15.6875/tr - 13.4721*lt + 0.43577*t6
+	return math.Exp(f0 + w*f1)
+}
+
+// LeeKesler evaluates the Lee–Kesler generalized correlation, interpolating
+// between the simple fluid (ω = 0) and the n-octane reference fluid in ω.
+// The phase is chosen with the Lee–Kesler vapour pressure correlation:
+// below Tc the state is liquid above Psat(T) and vapour below it.
+//
+// The result has the same shape as Solve's with a single volume; A and B
+// are not defined for this model and are left 0.
+func LeeKesler(T, P, Tc, Pc, W, R float64) (Result, error) {
+	err := errors.Join(positive("T", T), positive("P", P), positive("Tc", Tc),
+		positive("Pc", Pc), finite("W", W), positive("R", R))
+	if err != nil {
+		return Result{}, err
+	}
+
+	tr, pr := T/Tc, P/Pc
+	phase := PhaseSupercritical
+	if tr < 1 {
+		phase = PhaseVapour
+		if pr > lkSaturationPr(tr, W) {
+			phase = PhaseLiquid
+		}
+	}
+
+	solve := func(f lkFluid) (vr, lnPhi, hr, sr float64, err error) {
+		k := f.coeffs(tr)
+		vr, ok := f.volume(k, tr, pr, phase == PhaseLiquid)
+		if !ok {
+			// the fluid has no root on the requested branch at this state
+			// (or is supercritical); take the other one
+			if vr, ok = f.volume(k, tr, pr, phase != PhaseLiquid); !ok {
+				return 0, 0, 0, 0, ErrNoPhysicalRoot
+			}
+		}
+		lnPhi, hr, sr = f.residual(k, tr, vr)
+		return vr, lnPhi, hr, sr, nil
+	}
+
+	v0, lnPhi0, hr0, sr0, err := solve(lkSimple)
+	if err != nil {
+		return Result{}, err
+	}
+	vR, lnPhiR, hrR, srR, err := solve(lkReference)
+	if err != nil {
+		return Result{}, err
+	}
+	z0, zR := pr*v0/tr, pr*vR/tr
+
+	mix := func(simple, ref float64) float64 {
+		return simple + W/lkOmegaRef*(ref-simple)
+	}
+	res := Result{EOS: "Lee-Kesler", Phase: phase, NRoots: 1, Stable: 0, Metastable: -1}
+	res.Z[0] = mix(z0, zR)
+	res.Volumes[0] = res.Z[0] * R * T / P
+	res.LnPhi[0] = mix(lnPhi0, lnPhiR)
+	res.HR[0] = R * Tc * mix(hr0, hrR)
+	res.SR[0] = R * mix(sr0, srR)
+	return res, nil
+}
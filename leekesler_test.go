@@ -0,0 +1,52 @@
+package cubiceos
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLeeKeslerTables(t *testing.T) {
+	// Z⁰ and Z¹ of the Lee–Kesler tables, the second pair interpolated in
+	// the n-butane example of Smith, Van Ness and Abbott (510 K, 25 bar)
+	for _, tc := range []struct {
+		tr, pr, z0, z1, tol float64
+	}{
+		{0.7, 0.01, 0.9904, -0.0093, 1e-4},
+		{1.2, 0.6568, 0.865, 0.038, 1e-3},
+	} {
+		simple, err := LeeKesler(tc.tr, tc.pr, 1, 1, 0, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ref, err := LeeKesler(tc.tr, tc.pr, 1, 1, lkOmegaRef, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		z0, z1 := simple.Z[0], (ref.Z[0]-simple.Z[0])/lkOmegaRef
+		if math.Abs(z0-tc.z0) > tc.tol || math.Abs(z1-tc.z1) > tc.tol {
+			t.Errorf("Tr = %g, Pr = %g: Z⁰ = %.4f, Z¹ = %.4f; want %g, %g", tc.tr, tc.pr, z0, z1, tc.z0, tc.z1)
+		}
+	}
+}
+
+func TestLeeKeslerUnits(t *testing.T) {
+	// n-butane at 510 K and 25 bar; H^R and S^R scale with R, Z and ln φ
+	// do not
+	one, err := LeeKesler(510, 25, 425.1, 37.96, 0.2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	si, err := LeeKesler(510, 25, 425.1, 37.96, 0.2, 8.314)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(si.Z[0]-0.873) > 1e-3 {
+		t.Errorf("Z = %g, want 0.873", si.Z[0])
+	}
+	if si.Z[0] != one.Z[0] || si.LnPhi[0] != one.LnPhi[0] {
+		t.Errorf("Z or ln φ depend on R: %+v, %+v", one, si)
+	}
+	if math.Abs(si.HR[0]-8.314*one.HR[0]) > 1e-9*math.Abs(si.HR[0]) || math.Abs(si.SR[0]-8.314*one.SR[0]) > 1e-9*math.Abs(si.SR[0]) {
+		t.Errorf("H^R %g, S^R %g with R = 8.314; %g, %g with R = 1", si.HR[0], si.SR[0], one.HR[0], one.SR[0])
+	}
+}
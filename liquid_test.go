@@ -0,0 +1,86 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// propane at 300 K: the measured saturated liquid is about 490 kg/m³,
+// 90.0 cm³/mol
+const propaneVL300 = 90.0
+
+func TestRackett(t *testing.T) {
+	// Spencer–Danner Z_RA = 0.2766 for propane
+	v, err := Rackett(300, 369.8, 42.48, 0.152, 0.2766, barCm3R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(v-90.1196) > 1e-3 || math.Abs(v-propaneVL300) > 0.005*propaneVL300 {
+		t.Errorf("Rackett V = %g cm³/mol, want 90.1196", v)
+	}
+	// Yamada–Gunn Z_RA from ω
+	if v, err := Rackett(300, 369.8, 42.48, 0.152, 0, barCm3R); err != nil || math.Abs(v-90.4483) > 1e-3 {
+		t.Errorf("Rackett with estimated Z_RA = %g, %v; want 90.4483", v, err)
+	}
+}
+
+func TestCOSTALD(t *testing.T) {
+	// Hankinson–Thomson characteristic volume V* = 200.0 cm³/mol and
+	// ω_SRK = 0.1532 for propane; Psat(300 K) is 10.01 bar
+	for _, tc := range []struct {
+		P, want float64
+	}{
+		{1, 90.0522},   //saturated: below Psat the pressure is ignored
+		{200, 82.6440}, //compressed by Thomson's Tait correction
+	} {
+		v, err := COSTALD(300, tc.P, 369.8, 42.48, 0.1532, 200.0, barCm3R)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(v-tc.want) > 1e-3 {
+			t.Errorf("COSTALD V(300 K, %g bar) = %g cm³/mol, want %g", tc.P, v, tc.want)
+		}
+	}
+	if v, _ := COSTALD(300, 1, 369.8, 42.48, 0.1532, 200.0, barCm3R); math.Abs(v-propaneVL300) > 0.005*propaneVL300 {
+		t.Errorf("COSTALD saturated V = %g, measured %g", v, propaneVL300)
+	}
+}
+
+func TestLiquidVolumes(t *testing.T) {
+	cfg := propaneCfg
+	lv, err := cfg.LiquidVolumes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Solve(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cubic, _ := res.Liquid()
+	rackett, _ := Rackett(cfg.T, cfg.Tc, cfg.Pc, cfg.W, 0, cfg.R)
+	costald, _ := COSTALD(cfg.T, cfg.P, cfg.Tc, cfg.Pc, cfg.W, 0, cfg.R)
+	if lv.Cubic != cubic || lv.Rackett != rackett || lv.COSTALD != costald {
+		t.Errorf("LiquidVolumes %+v; cubic %g, Rackett %g, COSTALD %g", lv, cubic, rackett, costald)
+	}
+	if d := lv.Deviation(lv.Rackett); math.Abs(d-100*(cubic-rackett)/rackett) > 1e-12 {
+		t.Errorf("Deviation = %g", d)
+	}
+	if !math.IsNaN((LiquidVolumes{}).Deviation(90)) {
+		t.Error("Deviation without a liquid root is not NaN")
+	}
+}
+
+func TestLiquidVolumesSupercritical(t *testing.T) {
+	cfg := propaneCfg
+	cfg.T = cfg.Tc
+	if _, err := cfg.LiquidVolumes(); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("LiquidVolumes at Tc: %v, want ErrInvalidInput", err)
+	}
+	if _, err := Rackett(400, 369.8, 42.48, 0.152, 0, barCm3R); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Rackett above Tc: %v, want ErrInvalidInput", err)
+	}
+	if _, err := COSTALD(369.8, 50, 369.8, 42.48, 0.152, 0, barCm3R); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("COSTALD at Tc: %v, want ErrInvalidInput", err)
+	}
+}
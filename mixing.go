@@ -0,0 +1,292 @@
+package cubiceos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// MixingRule combines pure-component EOS parameters into those of a
+// mixture
+type MixingRule interface {
+	// Mix returns the mixture b and q = a/(bRT) at T and mole fractions x,
+	// given the pure-component a_i(T) and b_i of an EOS with parameters p.
+	// It writes the partial molar quantities b̄_i = ∂(nb)/∂n_i and
+	// q̄_i = ∂(nq)/∂n_i into bBar and qBar.
+	Mix(p Params, T, R float64, x, a, b, bBar, qBar []float64) (bm, qm float64, err error)
+	Name() string
+}
+
+// GEModel is an activity coefficient (excess Gibbs energy) model, as used
+// by the GE mixing rules
+type GEModel interface {
+	// LnGamma writes ln γ_i at T and mole fractions x into lnGamma
+	LnGamma(T float64, x, lnGamma []float64)
+}
+
+// Classical is the van der Waals one-fluid mixing rule
+//
+//	a = ΣΣ x_i x_j √(a_i a_j)(1 - k_ij),  b = Σ x_i b_i
+//
+// with k_ij = K_ij + KT_ij T
+type Classical struct {
+	K  [][]float64 //binary interaction parameters k_ij, nil for all zero
+	KT [][]float64 //temperature slopes of k_ij, nil for constant k_ij
+}
+
+func (Classical) Name() string { return "classical" }
+
+func (r Classical) validate(n int) error {
+	return errors.Join(validateK("K", r.K, n), validateK("KT", r.KT, n))
+}
+
+func (r Classical) Mix(p Params, T, R float64, x, a, b, bBar, qBar []float64) (float64, float64, error) {
+	bm := linearB(x, b, bBar)
+	am := 0.0
+	for i := range x {
+		// hold Σ_j x_j a_ij in qBar until a is known
+		s := 0.0
+		for j := range x {
+			s += x[j] * math.Sqrt(a[i]*a[j]) * (1 - kij(r.K, i, j) - T*kij(r.KT, i, j))
+		}
+		qBar[i] = s
+		am += x[i] * s
+	}
+	qm := am / (bm * R * T)
+	for i := range x {
+		// ā_i = 2 Σ_j x_j a_ij - a
+		qBar[i] = qm * (1 + (2*qBar[i]-am)/am - bBar[i]/bm)
+	}
+	return bm, qm, nil
+}
+
+// HuronVidal equates the excess Gibbs energy of the EOS at infinite
+// pressure to that of GE:
+//
+//	q = Σ x_i q_i + (gE/RT)/C*,  b = Σ x_i b_i
+//
+// with q_i = a_i/(b_i RT) and C* = -ln((1+σ)/(1+ε))/(σ-ε), e.g. -ln 2 for SRK
+type HuronVidal struct {
+	GE GEModel
+}
+
+func (HuronVidal) Name() string { return "Huron-Vidal" }
+
+func (r HuronVidal) validate(int) error { return needGE(r.GE) }
+
+func (r HuronVidal) Mix(p Params, T, R float64, x, a, b, bBar, qBar []float64) (float64, float64, error) {
+	bm := linearB(x, b, bBar)
+	c := infinitePressureC(p)
+	r.GE.LnGamma(T, x, qBar)
+	qm := 0.0
+	for i := range x {
+		qBar[i] = a[i]/(b[i]*R*T) + qBar[i]/c
+		qm += x[i] * qBar[i]
+	}
+	return bm, qm, nil
+}
+
+// MHV1 is the first-order modified Huron–Vidal rule, matching GE at zero
+// pressure through the linear approximation
+//
+//	q = Σ x_i q_i + [gE/RT + Σ x_i ln(b/b_i)]/q1
+//
+// Q1 = 0 selects the published value for the EOS: -0.593 for SRK and RK,
+// -0.53 for PR
+type MHV1 struct {
+	GE GEModel
+	Q1 float64
+}
+
+func (MHV1) Name() string { return "MHV1" }
+
+func (r MHV1) validate(int) error { return needGE(r.GE) }
+
+func (r MHV1) Mix(p Params, T, R float64, x, a, b, bBar, qBar []float64) (float64, float64, error) {
+	q1 := r.Q1
+	if q1 == 0 {
+		var err error
+		if q1, _, err = mhvConstants(p); err != nil {
+			return 0, 0, err
+		}
+	}
+	bm := linearB(x, b, bBar)
+	r.GE.LnGamma(T, x, qBar)
+	qm := 0.0
+	for i := range x {
+		qBar[i] = a[i]/(b[i]*R*T) + (qBar[i]+math.Log(bm/b[i])+b[i]/bm-1)/q1
+		// Σ x_i (b_i/b - 1) = 0, so q is the mole-fraction average of q̄_i
+		qm += x[i] * qBar[i]
+	}
+	return bm, qm, nil
+}
+
+// MHV2 is the second-order modified Huron–Vidal rule, solving
+//
+//	q1(q - Σ x_i q_i) + q2(q² - Σ x_i q_i²) = gE/RT + Σ x_i ln(b/b_i)
+//
+// for q. Q1 = Q2 = 0 select the published values for the EOS:
+// (-0.478, -0.0047) for SRK and RK, (-0.4347, -0.003654) for PR
+type MHV2 struct {
+	GE     GEModel
+	Q1, Q2 float64
+}
+
+func (MHV2) Name() string { return "MHV2" }
+
+func (r MHV2) validate(int) error { return needGE(r.GE) }
+
+func (r MHV2) Mix(p Params, T, R float64, x, a, b, bBar, qBar []float64) (float64, float64, error) {
+	q1, q2 := r.Q1, r.Q2
+	if q1 == 0 && q2 == 0 {
+		_, q, err := mhvConstants(p)
+		if err != nil {
+			return 0, 0, err
+		}
+		q1, q2 = q[0], q[1]
+	}
+	bm := linearB(x, b, bBar)
+	r.GE.LnGamma(T, x, qBar)
+
+	// c = gE/RT + Σ x_i [ln(b/b_i) + q1 q_i + q2 q_i²], so q2 q² + q1 q = c
+	c := 0.0
+	for i := range x {
+		qi := a[i] / (b[i] * R * T)
+		// qBar holds the right-hand side of the partial molar equation
+		qBar[i] += math.Log(bm/b[i]) + b[i]/bm - 1 + q1*qi + q2*qi*qi
+		c += x[i] * qBar[i]
+	}
+	var qm float64
+	if q2 == 0 {
+		qm = c / q1
+	} else {
+		d := q1*q1 + 4*q2*c
+		if d < 0 {
+			return 0, 0, fmt.Errorf("MHV2 has no real solution for q (discriminant %g)", d)
+		}
+		// the root that tends to c/q1 as q2 → 0
+		s := -0.5 * (q1 + math.Copysign(math.Sqrt(d), q1))
+		qm = -c / s
+	}
+	den := q1 + 2*q2*qm
+	for i := range x {
+		qBar[i] = (qBar[i] + q2*qm*qm) / den
+	}
+	return bm, qm, nil
+}
+
+// WongSandler equates the Helmholtz energy of the EOS at infinite pressure
+// to the excess Gibbs energy of GE while keeping a quadratic second virial
+// coefficient:
+//
+//	b = Q/(1 - D),  q = D
+//	Q = ΣΣ x_i x_j [(b_i - a_i/RT) + (b_j - a_j/RT)]/2 (1 - k_ij)
+//	D = Σ x_i q_i + (gE/RT)/C*
+//
+// with C* as for HuronVidal
+type WongSandler struct {
+	GE GEModel
+	K  [][]float64 //binary interaction parameters k_ij, nil for all zero
+}
+
+func (WongSandler) Name() string { return "Wong-Sandler" }
+
+func (r WongSandler) validate(n int) error {
+	return errors.Join(needGE(r.GE), validateK("K", r.K, n))
+}
+
+func (r WongSandler) Mix(p Params, T, R float64, x, a, b, bBar, qBar []float64) (float64, float64, error) {
+	rt := R * T
+	c := infinitePressureC(p)
+	r.GE.LnGamma(T, x, qBar)
+
+	// D̄_i = q_i + ln γ_i/C* and Q̄_i = 2 Σ_j x_j (b - a/RT)_ij
+	d, q := 0.0, 0.0
+	for i := range x {
+		qBar[i] = a[i]/(b[i]*rt) + qBar[i]/c
+		d += x[i] * qBar[i]
+		s := 0.0
+		for j := range x {
+			s += x[j] * 0.5 * (b[i] - a[i]/rt + b[j] - a[j]/rt) * (1 - kij(r.K, i, j))
+		}
+		bBar[i] = 2 * s
+		q += x[i] * s
+	}
+	bm := q / (1 - d)
+	if !(bm > 0) || !isFinite(bm) {
+		return 0, 0, fmt.Errorf("Wong-Sandler mixing gives b = %g <= 0", bm)
+	}
+	for i := range x {
+		bBar[i] = bBar[i]/(1-d) - q*(1-qBar[i])/((1-d)*(1-d))
+	}
+	return bm, d, nil
+}
+
+// infinitePressureC returns C* = lim_{V→b} (A^R - A^R_linear)/(RT) per unit
+// (a/bRT), -ln((1+σ)/(1+ε))/(σ-ε), which is -1/(1+ε) when σ = ε
+func infinitePressureC(p Params) float64 {
+	if p.Sigma == p.Epsilon {
+		return -1 / (1 + p.Epsilon)
+	}
+	return -math.Log((1+p.Sigma)/(1+p.Epsilon)) / (p.Sigma - p.Epsilon)
+}
+
+// mhvConstants returns the published MHV1 q1 and MHV2 (q1, q2) for the
+// Redlich–Kwong family (σ = 1, ε = 0) and Peng–Robinson
+func mhvConstants(p Params) (mhv1 float64, mhv2 [2]float64, err error) {
+	switch {
+	case p.Sigma == 1 && p.Epsilon == 0:
+		return -0.593, [2]float64{-0.478, -0.0047}, nil
+	case p.Sigma == PR{}.Params().Sigma && p.Epsilon == PR{}.Params().Epsilon:
+		return -0.53, [2]float64{-0.4347, -0.003654}, nil
+	}
+	return 0, [2]float64{}, fmt.Errorf("no published MHV constants for σ = %g, ε = %g; set Q1 (and Q2)", p.Sigma, p.Epsilon)
+}
+
+// linearB applies b = Σ x_i b_i, for which b̄_i = b_i
+func linearB(x, b, bBar []float64) float64 {
+	bm := 0.0
+	for i := range x {
+		bm += x[i] * b[i]
+		bBar[i] = b[i]
+	}
+	return bm
+}
+
+func kij(k [][]float64, i, j int) float64 {
+	if k == nil {
+		return 0
+	}
+	return k[i][j]
+}
+
+// validateK checks that k, the field called name, is nil or an n×n
+// symmetric matrix
+func validateK(name string, k [][]float64, n int) error {
+	if k == nil {
+		return nil
+	}
+	if len(k) != n {
+		return fmt.Errorf("%w: %s has %d rows for %d components", ErrInvalidInput, name, len(k), n)
+	}
+	var errs []error
+	for i := range k {
+		if len(k[i]) != n {
+			errs = append(errs, fmt.Errorf("%w: %s row %d has %d entries for %d components", ErrInvalidInput, name, i, len(k[i]), n))
+			continue
+		}
+		for j := range i {
+			if len(k[j]) == n && k[i][j] != k[j][i] {
+				errs = append(errs, &InvalidInputError{Field: fmt.Sprintf("%s[%d][%d]", name, i, j), Value: k[i][j], Constraint: fmt.Sprintf("= %s[%d][%d] (%g)", name, j, i, k[j][i])})
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func needGE(ge GEModel) error {
+	if ge == nil {
+		return fmt.Errorf("%w: mixing rule has no GE model set", ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package cubiceos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/rickykimani/cubiceos/activity"
+)
+
+// Component is a pure compound taking part in a mixture
+type Component struct {
+	Name   string
+	Tc     float64         //Critical temp (Absolute)
+	Pc     float64         //Critical pressure
+	W      float64         //Acentric factor (SRK and PR only)
+	Alpha  AlphaModel      //replaces the EOS alpha function when set
+	C      float64         //volume translation, V = V_EOS - C
+	M      float64         //molar mass, optional; set by Characterize
+	CPA    CPA             //association and fitted SRK parameters, for a CPA mixture
+	Groups activity.Groups //UNIFAC subgroups, for NewPSRK and NewVTPR
+}
+
+// Mixture is a cubic EOS applied to a multicomponent mixture. The pure
+// component a_i(T) and b_i come from the EOS exactly as for a single
+// compound; the mixing rule combines them into the mixture a and b. Like Compiled, a
+// Mixture is immutable and safe for concurrent use.
+type Mixture struct {
+	name  string
+	p     Params
+	r     float64
+	comps []Component
+	pure  []*Compiled
+	rule  MixingRule
+	assoc *assocFluid //association term, CPA only
+}
+
+// NewMixture binds eos and rule to the components, using the gas constant
+// R. A nil rule selects Classical with every kij = 0. With CPA{} as eos
+// each component's CPA field supplies its association and fitted SRK
+// parameters; a zero CPA is plain SRK.
+func NewMixture(eos EOSType, comps []Component, rule MixingRule, R float64) (*Mixture, error) {
+	var errs []error
+	if eos == nil {
+		errs = append(errs, ErrNoEOSType)
+	}
+	if len(comps) == 0 {
+		errs = append(errs, &InvalidInputError{Field: "components", Value: 0, Constraint: ">= 1"})
+	}
+	errs = append(errs, positive("R", R))
+	if rule == nil {
+		rule = Classical{}
+	}
+	if v, ok := rule.(interface{ validate(n int) error }); ok {
+		errs = append(errs, v.validate(len(comps)))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	m := &Mixture{
+		name:  eos.Name(),
+		p:     eos.Params(),
+		r:     R,
+		comps: append([]Component(nil), comps...),
+		pure:  make([]*Compiled, len(comps)),
+		rule:  rule,
+	}
+	_, isCPA := asCPA(eos)
+	if isCPA {
+		m.assoc = &assocFluid{sites: make([]assocSite, len(comps))}
+	}
+	for i, c := range comps {
+		ceos := eos
+		if isCPA {
+			ceos = c.CPA
+		}
+		pure, err := Compile(ceos, c.Tc, c.Pc, c.W, R)
+		if err != nil {
+			return nil, fmt.Errorf("component %d (%s): %w", i, c.Name, err)
+		}
+		if err := finite("C", c.C); err != nil {
+			return nil, fmt.Errorf("component %d (%s): %w", i, c.Name, err)
+		}
+		if c.Alpha != nil {
+			pure.alpha = c.Alpha.Alpha
+		}
+		if isCPA {
+			m.assoc.sites[i] = c.CPA.site(pure.b)
+		}
+		m.pure[i] = pure
+	}
+	return m, nil
+}
+
+// Name returns the name of the EOS and of the mixing rule
+func (m *Mixture) Name() string { return m.name + " / " + m.rule.Name() }
+
+// Len returns the number of components
+func (m *Mixture) Len() int { return len(m.comps) }
+
+// Components returns a copy of the components
+func (m *Mixture) Components() []Component { return append([]Component(nil), m.comps...) }
+
+// Pure returns the compiled pure-component EOS of component i
+func (m *Mixture) Pure(i int) *Compiled { return m.pure[i] }
+
+// composition checks x and returns it normalised to unit sum
+func (m *Mixture) composition(x []float64) ([]float64, error) {
+	if len(x) != len(m.comps) {
+		return nil, fmt.Errorf("%w: composition has %d entries for %d components", ErrInvalidInput, len(x), len(m.comps))
+	}
+	var errs []error
+	sum := 0.0
+	for i, xi := range x {
+		if !isFinite(xi) || xi < 0 {
+			errs = append(errs, &InvalidInputError{Field: fmt.Sprintf("x[%d]", i), Value: xi, Constraint: ">= 0"})
+			continue
+		}
+		sum += xi
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if sum <= 0 {
+		return nil, &InvalidInputError{Field: "sum(x)", Value: sum, Constraint: "> 0"}
+	}
+	out := make([]float64, len(x))
+	for i, xi := range x {
+		out[i] = xi / sum
+	}
+	return out, nil
+}
+
+// mix evaluates the mixing rule at T and the normalised composition x,
+// filling bBar and qBar
+func (m *Mixture) mix(T float64, x, bBar, qBar []float64) (b, q float64, err error) {
+	n := len(m.pure)
+	a, bi := make([]float64, n), make([]float64, n)
+	for i, c := range m.pure {
+		a[i], bi[i] = c.A(T), c.b
+	}
+	return m.rule.Mix(m.p, T, m.r, x, a, bi, bBar, qBar)
+}
+
+// AB returns the mixture a(T) and b at composition x
+func (m *Mixture) AB(T float64, x []float64) (a, b float64, err error) {
+	if err := positive("T", T); err != nil {
+		return 0, 0, err
+	}
+	x, err = m.composition(x)
+	if err != nil {
+		return 0, 0, err
+	}
+	n := len(x)
+	b, q, err := m.mix(T, x, make([]float64, n), make([]float64, n))
+	if err != nil {
+		return 0, 0, err
+	}
+	return q * b * m.r * T, b, nil
+}
+
+// LnPhi returns the component ln fugacity coefficients, and Z, of the
+// requested phase (PhaseLiquid or PhaseVapour) of composition x at T and
+// P. The volume root is found as by Compiled.Density; when only one root
+// exists it is used whatever the phase. The coefficients follow from the
+// partial molar b̄_i and q̄_i of the mixing rule:
+//
+//	ln φ_i = (b̄_i/b)(Z - 1) - ln(Z - β) - q̄_i I - C_i P/RT
+//
+// where the last term is the volume translation, which also shifts Z by
+// -Σ x_i C_i P/RT
+func (m *Mixture) LnPhi(T, P float64, x []float64, phase Phase) (lnPhi []float64, z float64, err error) {
+	if err := errors.Join(positive("T", T), positive("P", P)); err != nil {
+		return nil, 0, err
+	}
+	if phase != PhaseLiquid && phase != PhaseVapour {
+		return nil, 0, &InvalidInputError{Field: "phase", Value: float64(phase), Constraint: "liquid or vapour"}
+	}
+	x, err = m.composition(x)
+	if err != nil {
+		return nil, 0, err
+	}
+	lnPhi, z, _, err = m.fugacity(T, P, x, phase)
+	return lnPhi, z, err
+}
+
+// fugacity implements LnPhi for a normalised x. PhaseNone selects the
+// root with the lower Gibbs energy, Σ x_i ln φ_i; the returned phase is
+// the one of the root used, judged against the EOS critical volume when
+// only one root exists.
+func (m *Mixture) fugacity(T, P float64, x []float64, phase Phase) (lnPhi []float64, z float64, kind Phase, err error) {
+	n := len(x)
+	bBar, qBar := make([]float64, n), make([]float64, n)
+	b, q, err := m.mix(T, x, bBar, qBar)
+	if err != nil {
+		return nil, 0, PhaseNone, err
+	}
+	rt := m.r * T
+	eval := func(phase Phase) ([]float64, float64, float64, error) {
+		if m.assoc != nil {
+			return m.cpaFugacity(T, P, x, b, q, bBar, qBar, phase)
+		}
+		d, err := density(m.p, q*b*rt, b, T, P, m.r, phase, 0)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		z := d.Z
+		beta := b * P / rt
+		i := integralI(m.p, z, beta)
+		lnZB := math.Log(z - beta)
+		lnPhi := make([]float64, n)
+		zt := z
+		for k := range lnPhi {
+			shift := m.comps[k].C * P / rt
+			lnPhi[k] = bBar[k]/b*(z-1) - lnZB - qBar[k]*i - shift
+			zt -= x[k] * shift
+		}
+		return lnPhi, zt, d.V, nil
+	}
+
+	if phase != PhaseNone {
+		lnPhi, z, _, err = eval(phase)
+		return lnPhi, z, phase, err
+	}
+	lnL, zL, vL, err := eval(PhaseLiquid)
+	if err != nil {
+		return nil, 0, PhaseNone, err
+	}
+	lnV, zV, vV, err := eval(PhaseVapour)
+	if err != nil {
+		return nil, 0, PhaseNone, err
+	}
+	if math.Abs(vL-vV) <= 1e-9*vL {
+		if vL < criticalZ(m.p)*b/m.p.Omega {
+			return lnL, zL, PhaseLiquid, nil
+		}
+		return lnV, zV, PhaseVapour, nil
+	}
+	gL, gV := 0.0, 0.0
+	for k := range x {
+		gL += x[k] * lnL[k]
+		gV += x[k] * lnV[k]
+	}
+	if gL <= gV {
+		return lnL, zL, PhaseLiquid, nil
+	}
+	return lnV, zV, PhaseVapour, nil
+}
@@ -0,0 +1,25 @@
+package cubiceos
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewMixtureInvalid(t *testing.T) {
+	comps := []Component{
+		{Name: "methane", Tc: 190.6, Pc: 46.0, W: 0.012},
+		{Name: "propane", Tc: 369.8, Pc: 42.48, W: 0.152},
+	}
+	for name, tc := range map[string]struct {
+		comps []Component
+		rule  MixingRule
+	}{
+		"no components": {nil, nil},
+		"no GE model":   {comps, MHV1{}},
+		"no VTPR GE":    {comps, VTPRRule{}},
+	} {
+		if _, err := NewMixture(PR{}, tc.comps, tc.rule, barCm3R); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: %v, want ErrInvalidInput", name, err)
+		}
+	}
+}
@@ -11,6 +11,10 @@ func (PR) Alpha(tr, w float64) float64 {
 	return c * c
 }
 
+func (PR) compileAlpha(w float64) alphaFunc {
+	return soaveAlpha(0.37464 + 1.54226*w - 0.26992*w*w)
+}
+
 func (PR) Params() Params {
 	return Params{
 		Sigma:   1 + math.Sqrt2,
@@ -0,0 +1,120 @@
+package cubiceos
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/rickykimani/cubiceos/activity"
+)
+
+// psrkQ1 is the MHV1 constant of PSRK (Holderbaum & Gmehling, 1991)
+const psrkQ1 = -0.64663
+
+// vtprC is the constant of the VTPR mixing rule (Ahlers & Gmehling, 2001)
+const vtprC = -0.53087
+
+// VTPRRule is the mixing rule of the volume-translated Peng–Robinson model,
+//
+//	b = ΣΣ x_i x_j b_ij,  b_ij^(3/4) = (b_i^(3/4) + b_j^(3/4))/2
+//	q = Σ x_i q_i + (gE/RT)/(-0.53087)
+//
+// GE should be a residual-only model (see activity.UNIFAC.Residual); the
+// combinatorial part is accounted for by the nonlinear b
+type VTPRRule struct {
+	GE GEModel
+}
+
+func (VTPRRule) Name() string { return "VTPR" }
+
+func (r VTPRRule) validate(int) error { return needGE(r.GE) }
+
+func (r VTPRRule) Mix(p Params, T, R float64, x, a, b, bBar, qBar []float64) (float64, float64, error) {
+	bm := 0.0
+	for i := range x {
+		// hold Σ_j x_j b_ij in bBar until b is known
+		s := 0.0
+		for j := range x {
+			bij := math.Pow((math.Pow(b[i], 0.75)+math.Pow(b[j], 0.75))/2, 4.0/3)
+			s += x[j] * bij
+		}
+		bBar[i] = s
+		bm += x[i] * s
+	}
+	for i := range x {
+		bBar[i] = 2*bBar[i] - bm
+	}
+
+	r.GE.LnGamma(T, x, qBar)
+	qm := 0.0
+	for i := range x {
+		qBar[i] = a[i]/(b[i]*R*T) + qBar[i]/vtprC
+		qm += x[i] * qBar[i]
+	}
+	return bm, qm, nil
+}
+
+// NewPSRK builds a predictive Soave–Redlich–Kwong mixture: SRK with
+// MHV1 (q1 = -0.64663) and UNIFAC from the components' Groups, using table
+// (the embedded activity.DefaultUNIFACTable when nil). Components should
+// set a MathiasCopeman Alpha; those that do not use the Soave α(ω).
+//
+// The embedded table is original UNIFAC for liquids only. It has none of
+// the PSRK gas groups (CH4, CO2, N2, H2S, H2, ...), so mixtures with gases
+// need a PSRK table passed in; a group missing from the table is an error.
+func NewPSRK(comps []Component, table *activity.UNIFACTable, R float64) (*Mixture, error) {
+	u, err := unifacFor(comps, table)
+	if err != nil {
+		return nil, err
+	}
+	return NewMixture(SRK{}, comps, MHV1{GE: u, Q1: psrkQ1}, R)
+}
+
+// NewVTPR builds a volume-translated Peng–Robinson mixture: PR with
+// VTPRRule and the residual part of UNIFAC from the components' Groups,
+// using table (the embedded activity.DefaultUNIFACTable when nil).
+// Components should set a Twu Alpha and their volume translation C;
+// those without an Alpha use the Soave α(ω) of PR.
+//
+// Published VTPR uses its own group parameters, which are not embedded:
+// with a nil table the result is VTPR's form with original UNIFAC
+// parameters, not a quantitative VTPR prediction. Pass the VTPR table for
+// that. A group missing from the table is an error.
+func NewVTPR(comps []Component, table *activity.UNIFACTable, R float64) (*Mixture, error) {
+	u, err := unifacFor(comps, table)
+	if err != nil {
+		return nil, err
+	}
+	return NewMixture(PR{}, comps, VTPRRule{GE: u.Residual()}, R)
+}
+
+// unifacFor builds the UNIFAC model of the components' Groups, checking
+// first that every group is in table
+func unifacFor(comps []Component, table *activity.UNIFACTable) (activity.UNIFAC, error) {
+	if table == nil {
+		table = activity.DefaultUNIFACTable()
+	}
+	groups := make([]activity.Groups, len(comps))
+	var errs []error
+	for i, c := range comps {
+		if len(c.Groups) == 0 {
+			errs = append(errs, fmt.Errorf("%w: component %d (%s) has no UNIFAC groups", ErrInvalidInput, i, c.Name))
+		}
+		for _, name := range slices.Sorted(maps.Keys(c.Groups)) {
+			if _, ok := table.Subgroups[name]; !ok {
+				errs = append(errs, fmt.Errorf("%w: component %d (%s): subgroup %q is not in the UNIFAC table", ErrInvalidInput, i, c.Name, name))
+			}
+		}
+		groups[i] = c.Groups
+	}
+	if err := errors.Join(errs...); err != nil {
+		return activity.UNIFAC{}, err
+	}
+	u, err := activity.NewUNIFAC(groups, table)
+	if err != nil {
+		return activity.UNIFAC{}, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	return u, nil
+}
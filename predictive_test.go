@@ -0,0 +1,26 @@
+package cubiceos
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rickykimani/cubiceos/activity"
+)
+
+func TestPSRKMissingGroups(t *testing.T) {
+	comps := []Component{
+		{Name: "methane", Tc: 190.6, Pc: 46.0, W: 0.012, Groups: activity.Groups{"CH4": 1}},
+		{Name: "ethanol", Tc: 513.9, Pc: 61.48, W: 0.645, Groups: activity.Groups{"CH3": 1, "CH2": 1, "OH": 1}},
+	}
+	for name, build := range map[string]func([]Component, *activity.UNIFACTable, float64) (*Mixture, error){
+		"PSRK": NewPSRK,
+		"VTPR": NewVTPR,
+	} {
+		if _, err := build(comps, nil, barCm3R); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s with CH4: %v, want ErrInvalidInput", name, err)
+		}
+		if _, err := build(comps[1:], nil, barCm3R); err != nil {
+			t.Errorf("%s with ethanol: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package cubiceos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// PseudoCorrelation selects a petroleum fraction correlation
+type PseudoCorrelation int
+
+const (
+	// DefaultCorrelation is Riazi–Daubert for Tc, Pc and M and Edmister
+	// for ω
+	DefaultCorrelation PseudoCorrelation = iota
+	// RiaziDaubert is Riazi and Daubert (1980), for Tc, Pc and M
+	RiaziDaubert
+	// KeslerLee is Kesler and Lee (1976), for Tc, Pc, M and ω
+	KeslerLee
+	// Edmister is Edmister (1958), for ω
+	Edmister
+)
+
+func (c PseudoCorrelation) String() string {
+	switch c {
+	case DefaultCorrelation:
+		return "default"
+	case RiaziDaubert:
+		return "Riazi-Daubert"
+	case KeslerLee:
+		return "Kesler-Lee"
+	case Edmister:
+		return "Edmister"
+	}
+	return fmt.Sprintf("PseudoCorrelation(%d)", int(c))
+}
+
+// PseudoOptions selects the correlations Characterize uses
+type PseudoOptions struct {
+	Critical PseudoCorrelation //Tc, Pc and M: RiaziDaubert or KeslerLee
+	Acentric PseudoCorrelation //ω: Edmister or KeslerLee
+}
+
+// Pseudo describes a petroleum fraction, e.g. a distillation cut or a
+// plus fraction
+type Pseudo struct {
+	Name string
+	Tb   float64 //normal boiling point (K); 0 to estimate it from M and SG
+	SG   float64 //specific gravity, 60 °F/60 °F
+	M    float64 //molar mass (g/mol); 0 to estimate it from Tb and SG
+}
+
+// Characterize turns a petroleum fraction into an ordinary Component with
+// Tc in K, Pc in bar and the molar mass, for use with NewSRKCfg, NewPRCfg
+// or a Mixture. Give Pc to an EOSCfg with R in matching units, e.g. 83.14
+// bar·cm³/(mol·K), or scale it. A plus fraction characterised by M and SG
+// alone has its boiling point estimated first (Riazi–Daubert, 1987).
+func Characterize(p Pseudo, opt PseudoOptions) (Component, error) {
+	errs := []error{positive("SG", p.SG)}
+	if p.Tb == 0 && p.M == 0 {
+		errs = append(errs, fmt.Errorf("%w: a pseudo-component needs Tb or M", ErrInvalidInput))
+	}
+	if p.Tb != 0 {
+		errs = append(errs, positive("Tb", p.Tb))
+	}
+	if p.M != 0 {
+		errs = append(errs, positive("M", p.M))
+	}
+	switch opt.Critical {
+	case DefaultCorrelation, RiaziDaubert, KeslerLee:
+	default:
+		errs = append(errs, fmt.Errorf("%w: %v is not a Tc/Pc correlation", ErrInvalidInput, opt.Critical))
+	}
+	switch opt.Acentric {
+	case DefaultCorrelation, Edmister, KeslerLee:
+	default:
+		errs = append(errs, fmt.Errorf("%w: %v is not an acentric factor correlation", ErrInvalidInput, opt.Acentric))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Component{}, err
+	}
+
+	tb, sg := p.Tb*1.8, p.SG //°R
+	if tb == 0 {
+		tb = riaziDaubertTb(p.M, sg)
+	}
+	var tc, pc, m float64 //°R, psia, g/mol
+	if opt.Critical == KeslerLee {
+		tc, pc, m = keslerLeeCritical(tb, sg)
+	} else {
+		tc, pc, m = riaziDaubertCritical(tb, sg)
+	}
+	if p.M != 0 {
+		m = p.M
+	}
+	var w float64
+	if opt.Acentric == KeslerLee {
+		w = keslerLeeOmega(tb, sg, tc, pc)
+	} else {
+		w = edmisterOmega(tb, tc, pc)
+	}
+
+	c := Component{Name: p.Name, Tc: tc / 1.8, Pc: pc * psiaToBar, W: w, M: m}
+	if !isFinite(c.Tc) || !isFinite(c.Pc) || !isFinite(c.W) || !(tc > tb) || c.Pc <= 0 {
+		return Component{}, fmt.Errorf("%w: %s: Tb = %g K and SG = %g are outside the range of the correlations", ErrInvalidInput, p.Name, tb/1.8, sg)
+	}
+	return c, nil
+}
+
+const (
+	psiaToBar = 0.0689475729
+	atmPsia   = 14.6959488
+)
+
+// riaziDaubertCritical returns Tc (°R), Pc (psia) and M from Tb (°R) and
+// SG, Riazi and Daubert, Hydrocarbon Process. 59 (1980) 115
+func riaziDaubertCritical(tb, sg float64) (tc, pc, m float64) {
+	tc = 24.2787 * math.Pow(tb, 0.58848) * math.Pow(sg, 0.3596)
+	pc = 3.12281e9 * math.Pow(tb, -2.3125) * math.Pow(sg, 2.3201)
+	m = 4.5673e-5 * math.Pow(tb, 2.1962) * math.Pow(sg, -1.0164)
+	return tc, pc, m
+}
+
+// riaziDaubertTb returns Tb (°R) from M and SG, Riazi and Daubert, Ind.
+// Eng. Chem. Res. 26 (1987) 755
+func riaziDaubertTb(m, sg float64) float64 {
+	return 1928.3 - 1.695e5*math.Pow(m, -0.03522)*math.Pow(sg, 3.266)*
+		math.Exp(-4.922e-3*m-4.7685*sg+3.462e-3*m*sg)
+}
+
+// keslerLeeCritical returns Tc (°R), Pc (psia) and M from Tb (°R) and SG,
+// Kesler and Lee, Hydrocarbon Process. 55 (1976) 153
+func keslerLeeCritical(tb, sg float64) (tc, pc, m float64) {
+	tc = 341.7 + 811*sg + (0.4244+0.1174*sg)*tb + (0.4669-3.2623*sg)*1e5/tb
+	pc = math.Exp(8.3634 - 0.0566/sg -
+		(0.24244+2.2898/sg+0.11857/(sg*sg))*1e-3*tb +
+		(1.4685+3.648/sg+0.47227/(sg*sg))*1e-7*tb*tb -
+		(0.42019+1.6977/(sg*sg))*1e-10*tb*tb*tb)
+	m = -12272.6 + 9486.4*sg + (4.6523-3.3287*sg)*tb +
+		(1-0.77084*sg-0.02058*sg*sg)*(1.3437-720.79/tb)*1e7/tb +
+		(1-0.80882*sg+0.02226*sg*sg)*(1.8828-181.98/tb)*1e12/(tb*tb*tb)
+	return tc, pc, m
+}
+
+// edmisterOmega is Edmister's acentric factor from the boiling point
+func edmisterOmega(tb, tc, pc float64) float64 {
+	theta := tb / tc
+	return 3.0/7*theta/(1-theta)*math.Log10(pc/atmPsia) - 1
+}
+
+// keslerLeeOmega is Kesler and Lee's acentric factor: the Lee–Kesler
+// vapour pressure solved for ω at the normal boiling point below
+// Tb/Tc = 0.8, and a Watson K correlation above it
+func keslerLeeOmega(tb, sg, tc, pc float64) float64 {
+	theta := tb / tc
+	if theta > 0.8 {
+		kw := math.Cbrt(tb) / sg
+		return -7.904 + 0.1352*kw - 0.007465*kw*kw + 8.359*theta + (1.408-0.01063*kw)/theta
+	}
+	// ln Pr,sat = f0 + ω f1 at Tr = θ; lkSaturationPr(θ, 0) = e^f0
+	f0 := math.Log(lkSaturationPr(theta, 0))
+	f1 := math.Log(lkSaturationPr(theta, 1)) - f0
+	return (math.Log(atmPsia/pc) - f0) / f1
+}
@@ -0,0 +1,49 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCharacterizeInvalid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		p   Pseudo
+		opt PseudoOptions
+	}{
+		"no Tb or M":          {Pseudo{Name: "C7+", SG: 0.75}, PseudoOptions{}},
+		"critical":            {Pseudo{Name: "C7+", SG: 0.75, M: 100}, PseudoOptions{Critical: Edmister}},
+		"acentric":            {Pseudo{Name: "C7+", SG: 0.75, M: 100}, PseudoOptions{Acentric: RiaziDaubert}},
+		"outside correlation": {Pseudo{Name: "C7+", SG: 0.05, Tb: 300}, PseudoOptions{}},
+	} {
+		if _, err := Characterize(tc.p, tc.opt); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: %v, want ErrInvalidInput", name, err)
+		}
+	}
+	if _, err := Characterize(Pseudo{Name: "C7", SG: 0.727, Tb: 365}, PseudoOptions{}); err != nil {
+		t.Errorf("C7: %v", err)
+	}
+}
+
+func TestCharacterizeReference(t *testing.T) {
+	// the fraction of Ahmed's worked examples, Tb = 198 °F and SG = 0.7365,
+	// with Tc in °R and Pc in psia
+	p := Pseudo{Name: "C7", Tb: (198 + 459.67) / 1.8, SG: 0.7365}
+	for _, tc := range []struct {
+		opt          PseudoOptions
+		tc, pc, m, w float64
+	}{
+		{PseudoOptions{}, 990.4, 467.4, 96.3, 0.273},
+		{PseudoOptions{Critical: KeslerLee, Acentric: KeslerLee}, 980.6, 470.2, 98.6, 0.306},
+	} {
+		c, err := Characterize(p, tc.opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(c.Tc*1.8-tc.tc) > 0.1 || math.Abs(c.Pc/psiaToBar-tc.pc) > 0.1 ||
+			math.Abs(c.M-tc.m) > 0.1 || math.Abs(c.W-tc.w) > 1e-3 {
+			t.Errorf("%v/%v: Tc = %.1f °R, Pc = %.1f psia, M = %.1f, ω = %.3f; want %g, %g, %g, %g",
+				tc.opt.Critical, tc.opt.Acentric, c.Tc*1.8, c.Pc/psiaToBar, c.M, c.W, tc.tc, tc.pc, tc.m, tc.w)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package cubiceos
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ReducedCfg describes a state point in reduced variables only. No gas
+// constant or critical constants are needed: every cubic EOS reduces to a
+// function of Tr, Pr and ω alone.
+type ReducedCfg struct {
+	Type EOSType
+	Tr   float64 //Reduced temp T/Tc
+	Pr   float64 //Reduced pressure P/Pc
+	W    float64 //Acentric factor (SRK and PR only)
+}
+
+// Tr returns the reduced temperature T/Tc
+func (cfg EOSCfg) Tr() float64 { return cfg.T / cfg.Tc }
+
+// Pr returns the reduced pressure P/Pc
+func (cfg EOSCfg) Pr() float64 { return cfg.P / cfg.Pc }
+
+// Vr returns the pseudo-reduced volume V Pc/(R Tc) of the molar volume V,
+// the volume coordinate of generalized compressibility charts
+func (cfg EOSCfg) Vr(V float64) float64 { return V * cfg.Pc / (cfg.R * cfg.Tc) }
+
+// Reduced returns the reduced form of cfg
+func (cfg EOSCfg) Reduced() ReducedCfg {
+	return ReducedCfg{Type: cfg.Type, Tr: cfg.Tr(), Pr: cfg.Pr(), W: cfg.W}
+}
+
+// Validate checks every field of cfg and reports all violations at once,
+// like EOSCfg.Validate
+func (cfg ReducedCfg) Validate() error {
+	return errors.Join(validateType(cfg.Type), positive("Tr", cfg.Tr), positive("Pr", cfg.Pr), finite("W", cfg.W))
+}
+
+// SolveReduced solves the EOS in reduced variables. It is Solve with
+// R = Tc = Pc = 1, so the returned volumes are pseudo-reduced volumes
+// (see EOSCfg.Vr), A is a/(R²Tc²/Pc) and B is b Pc/(R Tc). Z is unchanged
+// by the reduction.
+func SolveReduced(cfg ReducedCfg) (Result, error) {
+	if err := cfg.Validate(); err != nil {
+		return Result{}, err
+	}
+	if _, ok := asCPA(cfg.Type); ok {
+		return Result{}, fmt.Errorf("%w: CPA has dimensional association parameters and no reduced form", ErrUnsupported)
+	}
+	return Solve(EOSCfg{Type: cfg.Type, T: cfg.Tr, P: cfg.Pr, Tc: 1, Pc: 1, W: cfg.W, R: 1})
+}
@@ -0,0 +1,18 @@
+package cubiceos
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReducedValidate(t *testing.T) {
+	// the type's own checks run for the reduced form too
+	cfg := ReducedCfg{Type: CPA{A0: -1}, Tr: 0.9, Pr: 0.5, W: waterW}
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Validate: %v, want ErrInvalidInput", err)
+	}
+	cfg.Type = WithAlpha{EOS: PR{}}
+	if _, err := SolveReduced(cfg); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("SolveReduced: %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,345 @@
+package cubiceos
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Phase classifies the physically meaningful roots of a cubic EOS
+type Phase int
+
+const (
+	PhaseNone          Phase = iota // no physically meaningful root
+	PhaseLiquid                     // single liquid-like root below Tc
+	PhaseVapour                     // single vapour-like root below Tc
+	PhaseSupercritical              // single root at or above Tc
+	PhaseTwoRoot                    // liquid-like and vapour-like roots both present
+	PhaseCritical                   // the roots have coalesced at the critical point
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseNone:
+		return "none"
+	case PhaseLiquid:
+		return "liquid"
+	case PhaseVapour:
+		return "vapour"
+	case PhaseSupercritical:
+		return "supercritical"
+	case PhaseTwoRoot:
+		return "two-root"
+	case PhaseCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("Phase(%d)", int(p))
+	}
+}
+
+// Reasons a root of the cubic is not a physical molar volume
+const (
+	RejectComplex     = "complex"
+	RejectNonPositive = "V <= 0"
+	RejectBelowB      = "V <= b"
+)
+
+// RejectedRoot is a root of the cubic that is not a physical molar volume
+type RejectedRoot struct {
+	Root   complex128
+	Reason string
+}
+
+// Result is the interpreted solution of a cubic EOS at a single state point.
+// Fixed-size arrays are used so that a Result never allocates; only the
+// first NRoots entries of the per-volume arrays, and the first NRejected entries of
+// Rejected, are meaningful.
+type Result struct {
+	EOS     string
+	Phase   Phase
+	Volumes [3]float64 //physical molar volumes, ascending
+	Z       [3]float64 //compressibility factor of each volume
+	LnPhi   [3]float64 //ln fugacity coefficient of each volume
+	HR      [3]float64 //residual enthalpy H - H^ig of each volume, in the energy units implied by R
+	SR      [3]float64 //residual entropy S - S^ig of each volume, in the units of R
+	NRoots  int
+	// Stable indexes the volume with the lowest Gibbs energy, i.e. the
+	// lowest fugacity, among the mechanically stable roots. Metastable
+	// indexes the other mechanically stable root when there is one. Both
+	// are -1 when not applicable.
+	Stable     int
+	Metastable int
+	A          float64 //a(T)
+	B          float64 //b
+	Rejected   [3]RejectedRoot
+	NRejected  int
+}
+
+const (
+	// imagTol is the relative size of the imaginary part below which a
+	// root is considered real
+	imagTol = 1e-9
+	// criticalTol is the relative spread below which three roots are
+	// considered to have coalesced
+	criticalTol = 1e-4
+)
+
+// Solve solves the cubic EOS described by cfg and interprets its roots
+func Solve(cfg EOSCfg) (Result, error) {
+	if _, ok := asCPA(cfg.Type); ok {
+		// not cubic in V: the compiled form scans the pressure curve
+		if err := cfg.Validate(); err != nil {
+			return Result{}, err
+		}
+		c, err := Compile(cfg.Type, cfg.Tc, cfg.Pc, cfg.W, cfg.R)
+		if err != nil {
+			return Result{}, err
+		}
+		return c.Solve(cfg.T, cfg.P)
+	}
+	roots, err := CubicEOS(cfg)
+	if err != nil {
+		return Result{}, err
+	}
+	return classify(cfg.point(), roots), nil
+}
+
+// point is a state point with everything needed to interpret its roots
+type point struct {
+	name    string
+	p       Params
+	T, P, R float64
+	Tc      float64
+	Vc      float64 //critical volume predicted by the EOS
+	a, b    float64
+	dlnA    float64 //d ln α/d ln Tr
+}
+
+func (cfg EOSCfg) point() point {
+	a, b := cfg.ab()
+	p := cfg.Type.Params()
+	_, dlnA := compileAlpha(cfg.Type, cfg.W)(cfg.Tr())
+	return point{
+		name: cfg.Type.Name(),
+		p:    p,
+		T:    cfg.T, P: cfg.P, R: cfg.R,
+		Tc: cfg.Tc,
+		Vc: criticalZ(p) * cfg.R * cfg.Tc / cfg.Pc,
+		a:  a, b: b,
+		dlnA: dlnA,
+	}
+}
+
+// fill sets Z and the residual properties of volume i of res to v
+func (pt point) fill(res *Result, i int, v float64) {
+	rt := pt.R * pt.T
+	z := pt.P * v / rt
+	beta := pt.b * pt.P / rt
+	q := pt.a / (pt.b * rt)
+	qi := q * integralI(pt.p, z, beta)
+	res.Volumes[i] = v
+	res.Z[i] = z
+	res.LnPhi[i] = z - 1 - math.Log(z-beta) - qi
+	res.HR[i] = rt * (z - 1 + (pt.dlnA-1)*qi)
+	res.SR[i] = pt.R * (math.Log(z-beta) + pt.dlnA*qi)
+}
+
+// classify filters the roots of the cubic down to physical molar volumes
+// and assigns a phase
+func classify(pt point, roots [3]complex128) Result {
+	res := Result{EOS: pt.name, A: pt.a, B: pt.b, Stable: -1, Metastable: -1}
+
+	// The critical point is checked on the raw roots: near it the
+	// solver may return a tiny spurious imaginary part.
+	if coalesced(roots) {
+		v := (real(roots[0]) + real(roots[1]) + real(roots[2])) / 3
+		if v > pt.b {
+			res.Phase = PhaseCritical
+			pt.fill(&res, 0, v)
+			res.NRoots = 1
+			res.Stable = 0
+			return res
+		}
+	}
+
+	for _, r := range roots {
+		reason := ""
+		switch {
+		case math.Abs(imag(r)) > imagTol*math.Max(1, math.Abs(real(r))):
+			reason = RejectComplex
+		case real(r) <= 0:
+			reason = RejectNonPositive
+		case real(r) <= pt.b:
+			reason = RejectBelowB
+		}
+		if reason != "" {
+			res.Rejected[res.NRejected] = RejectedRoot{Root: r, Reason: reason}
+			res.NRejected++
+			continue
+		}
+		res.Volumes[res.NRoots] = real(r)
+		res.NRoots++
+	}
+
+	vs := res.Volumes[:res.NRoots]
+	// insertion sort; at most three elements
+	for i := 1; i < len(vs); i++ {
+		for j := i; j > 0 && vs[j] < vs[j-1]; j-- {
+			vs[j], vs[j-1] = vs[j-1], vs[j]
+		}
+	}
+	for i, v := range vs {
+		pt.fill(&res, i, v)
+	}
+	res.Stable, res.Metastable = stableRoots(pt, &res)
+
+	switch res.NRoots {
+	case 0:
+		res.Phase = PhaseNone
+	case 1:
+		switch {
+		case pt.T >= pt.Tc:
+			res.Phase = PhaseSupercritical
+		case vs[0] < pt.Vc:
+			res.Phase = PhaseLiquid
+		default:
+			res.Phase = PhaseVapour
+		}
+	default:
+		res.Phase = PhaseTwoRoot
+	}
+
+	return res
+}
+
+// stableRoots compares the fugacity of the mechanically stable roots
+// (dP/dV < 0). At fixed T and P the root with the lower fugacity has the
+// lower Gibbs energy and is the stable phase; the other is metastable.
+func stableRoots(pt point, res *Result) (stable, metastable int) {
+	stable, metastable = -1, -1
+	for i, v := range res.Volumes[:res.NRoots] {
+		if res.NRoots > 1 && dPdV(pt.p, pt.a, pt.b, pt.T, pt.R, v) >= 0 {
+			continue
+		}
+		switch {
+		case stable < 0:
+			stable = i
+		case res.LnPhi[i] < res.LnPhi[stable]:
+			stable, metastable = i, stable
+		default:
+			metastable = i
+		}
+	}
+	return stable, metastable
+}
+
+// coalesced reports whether all three roots lie within criticalTol of
+// each other
+func coalesced(roots [3]complex128) bool {
+	lo, hi := real(roots[0]), real(roots[0])
+	scale := 0.0
+	for _, r := range roots {
+		lo = math.Min(lo, real(r))
+		hi = math.Max(hi, real(r))
+		scale = math.Max(scale, math.Abs(imag(r)))
+	}
+	scale = math.Max(scale, hi-lo)
+	return scale <= criticalTol*math.Abs(hi)
+}
+
+// criticalZ is the critical compressibility predicted by the EOS itself.
+// At the critical point the cubic in Z has a triple root, so 3Zc equals
+// minus the Z^2 coefficient evaluated at Tr = Pr = 1.
+func criticalZ(p Params) float64 {
+	return (1 - (p.Epsilon+p.Sigma-1)*p.Omega) / 3
+}
+
+// Liquid returns the liquid-like molar volume, if there is one
+func (r Result) Liquid() (float64, bool) {
+	switch r.Phase {
+	case PhaseLiquid, PhaseTwoRoot:
+		return r.Volumes[0], true
+	}
+	return 0, false
+}
+
+// Vapour returns the vapour-like molar volume, if there is one.
+// Supercritical and critical volumes are reported here too.
+func (r Result) Vapour() (float64, bool) {
+	switch r.Phase {
+	case PhaseVapour, PhaseSupercritical, PhaseCritical, PhaseTwoRoot:
+		return r.Volumes[r.NRoots-1], true
+	}
+	return 0, false
+}
+
+// Unstable returns the middle root of the two-root region, if present
+func (r Result) Unstable() (float64, bool) {
+	if r.Phase == PhaseTwoRoot && r.NRoots == 3 {
+		return r.Volumes[1], true
+	}
+	return 0, false
+}
+
+// StableVolume returns the molar volume of the thermodynamically stable root
+func (r Result) StableVolume() (float64, bool) {
+	if r.Stable < 0 {
+		return 0, false
+	}
+	return r.Volumes[r.Stable], true
+}
+
+// MetastableVolume returns the molar volume of the metastable root, if any
+func (r Result) MetastableVolume() (float64, bool) {
+	if r.Metastable < 0 {
+		return 0, false
+	}
+	return r.Volumes[r.Metastable], true
+}
+
+// StablePhase resolves the two-root region to the phase of the stable
+// root. For every other phase it returns Phase unchanged.
+func (r Result) StablePhase() Phase {
+	if r.Phase != PhaseTwoRoot || r.Stable < 0 {
+		return r.Phase
+	}
+	if r.Stable == 0 {
+		return PhaseLiquid
+	}
+	return PhaseVapour
+}
+
+// Stability labels the volume at index i as stable, metastable or unstable
+func (r Result) Stability(i int) string {
+	switch i {
+	case r.Stable:
+		return "stable"
+	case r.Metastable:
+		return "metastable"
+	}
+	return "unstable"
+}
+
+func (r Result) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s", r.EOS, r.Phase)
+	switch r.Phase {
+	case PhaseNone:
+		sb.WriteString("\nNo physically meaningful roots found")
+	case PhaseCritical:
+		fmt.Fprintf(&sb, "\nCritical point: Vc = %.4f (Z = %.4f)", r.Volumes[0], r.Z[0])
+	case PhaseTwoRoot:
+		n := r.NRoots - 1
+		fmt.Fprintf(&sb, "\nliquid root : %.4f (Z = %.4f, %s)", r.Volumes[0], r.Z[0], r.Stability(0))
+		if v, ok := r.Unstable(); ok {
+			fmt.Fprintf(&sb, "\nmiddle root : %.4f (unstable)", v)
+		}
+		fmt.Fprintf(&sb, "\nvapour root : %.4f (Z = %.4f, %s)", r.Volumes[n], r.Z[n], r.Stability(n))
+	default:
+		fmt.Fprintf(&sb, "\nV = %.4f (Z = %.4f)", r.Volumes[0], r.Z[0])
+	}
+	for _, rej := range r.Rejected[:r.NRejected] {
+		fmt.Fprintf(&sb, "\nrejected root %.4g (%s)", rej.Root, rej.Reason)
+	}
+	return sb.String()
+}
@@ -0,0 +1,129 @@
+package cubiceos
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSolvePhases(t *testing.T) {
+	// PR propane, Psat(300 K) = 9.99 bar
+	for _, tc := range []struct {
+		T, P     float64
+		phase    Phase
+		nRoots   int
+		stable   Phase
+		rejected int
+	}{
+		{300, 100, PhaseLiquid, 1, PhaseLiquid, 2},
+		{350, 1, PhaseVapour, 1, PhaseVapour, 2},
+		{300, 5, PhaseTwoRoot, 3, PhaseVapour, 0},
+		{400, 50, PhaseSupercritical, 1, PhaseSupercritical, 2},
+	} {
+		cfg := propaneCfg
+		cfg.T, cfg.P = tc.T, tc.P
+		res, err := Solve(cfg)
+		if err != nil {
+			t.Fatalf("T = %g, P = %g: %v", tc.T, tc.P, err)
+		}
+		if res.Phase != tc.phase || res.NRoots != tc.nRoots || res.StablePhase() != tc.stable || res.NRejected != tc.rejected {
+			t.Errorf("T = %g, P = %g: %s with %d roots, stable %s, %d rejected; want %s, %d, %s, %d",
+				tc.T, tc.P, res.Phase, res.NRoots, res.StablePhase(), res.NRejected, tc.phase, tc.nRoots, tc.stable, tc.rejected)
+		}
+		for i := range res.NRejected {
+			if res.Rejected[i].Reason != RejectComplex {
+				t.Errorf("T = %g, P = %g: root %v rejected as %q, want complex", tc.T, tc.P, res.Rejected[i].Root, res.Rejected[i].Reason)
+			}
+		}
+		vs := res.Volumes[:res.NRoots]
+		for i, v := range vs {
+			if v <= res.B || (i > 0 && v <= vs[i-1]) {
+				t.Errorf("T = %g, P = %g: volumes %v not ascending above b = %g", tc.T, tc.P, vs, res.B)
+			}
+		}
+	}
+}
+
+func TestSolveTwoRoot(t *testing.T) {
+	cfg := propaneCfg
+	cfg.P = 5
+	res, err := Solve(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stable != 2 || res.Metastable != 0 {
+		t.Errorf("stable %d, metastable %d; want the vapour 2 and the liquid 0", res.Stable, res.Metastable)
+	}
+	if res.Stability(1) != "unstable" {
+		t.Errorf("middle root is %s, want unstable", res.Stability(1))
+	}
+	if v, ok := res.Unstable(); !ok || v != res.Volumes[1] {
+		t.Errorf("Unstable() = %g, %t", v, ok)
+	}
+	if res.LnPhi[2] >= res.LnPhi[0] {
+		t.Errorf("stable vapour ln φ %g not below the liquid's %g", res.LnPhi[2], res.LnPhi[0])
+	}
+}
+
+func TestClassifyRejects(t *testing.T) {
+	pt := propaneCfg.point()
+	cfg := propaneCfg
+	cfg.P = 100
+	res, err := Solve(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt.P = cfg.P
+	got := classify(pt, [3]complex128{
+		complex(res.Volumes[0], 0),
+		complex(0.5*pt.b, 0),
+		complex(-10, 0),
+	})
+	if got.NRoots != 1 || got.Volumes[0] != res.Volumes[0] || got.Phase != PhaseLiquid {
+		t.Errorf("kept %d roots %v as %s, want the liquid %g", got.NRoots, got.Volumes, got.Phase, res.Volumes[0])
+	}
+	want := []string{RejectBelowB, RejectNonPositive}
+	if got.NRejected != len(want) {
+		t.Fatalf("%d rejected roots, want %d", got.NRejected, len(want))
+	}
+	for i, reason := range want {
+		if got.Rejected[i].Reason != reason {
+			t.Errorf("rejected root %v: %q, want %q", got.Rejected[i].Root, got.Rejected[i].Reason, reason)
+		}
+	}
+}
+
+func TestStablePhaseAcrossSaturation(t *testing.T) {
+	c, err := Compile(PR{}, propaneCfg.Tc, propaneCfg.Pc, propaneCfg.W, barCm3R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, T := range []float64{250, 300, 350} {
+		sat, err := c.Saturation(T)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tc := range []struct {
+			f    float64
+			want Phase
+		}{
+			{0.99, PhaseVapour},
+			{1.01, PhaseLiquid},
+		} {
+			res, err := c.Solve(T, tc.f*sat.P)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Phase != PhaseTwoRoot || res.StablePhase() != tc.want {
+				t.Errorf("T = %g, P = %g·Psat: %s, stable %s; want two-root, %s", T, tc.f, res.Phase, res.StablePhase(), tc.want)
+			}
+		}
+		// at Psat itself both roots have the same fugacity
+		res, err := c.Solve(T, sat.P)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := math.Abs(res.LnPhi[0] - res.LnPhi[res.NRoots-1]); d > 1e-8 {
+			t.Errorf("T = %g: ln φ of the two roots differ by %g at Psat", T, d)
+		}
+	}
+}
@@ -8,6 +8,10 @@ func (RK) Alpha(tr, w float64) float64 {
 	return 1 / math.Sqrt(tr)
 }
 
+func (RK) compileAlpha(w float64) alphaFunc {
+	return func(tr float64) (float64, float64) { return 1 / math.Sqrt(tr), -0.5 }
+}
+
 func (RK) Params() Params {
 	return Params{
 		Sigma:   1,
@@ -0,0 +1,96 @@
+package cubiceos
+
+import (
+	"fmt"
+	"math"
+)
+
+// Saturation is a pure-component vapour–liquid equilibrium point
+type Saturation struct {
+	P          float64 //vapour pressure
+	VL         float64 //saturated liquid molar volume
+	VV         float64 //saturated vapour molar volume
+	LnPhi      float64 //ln φ of both phases at saturation
+	Iterations int
+}
+
+const (
+	satMaxIter = 100
+	satTol     = 1e-12
+)
+
+// Saturation finds the vapour pressure of the EOS at T < Tc, where the
+// liquid and vapour roots have equal fugacity. It takes Newton steps in
+// ln P, using ∂(ln φ_L - ln φ_V)/∂ln P = Z_L - Z_V, and keeps a bracket
+// from the pressures already visited so that a step leaving the
+// three-root region is replaced by bisection.
+func (c *Compiled) Saturation(T float64) (Saturation, error) {
+	if err := positive("T", T); err != nil {
+		return Saturation{}, err
+	}
+	if T >= c.tc {
+		return Saturation{}, &InvalidInputError{Field: "T", Value: T, Constraint: fmt.Sprintf("< Tc (%g)", c.tc)}
+	}
+
+	// Lee–Kesler vapour pressure as the first guess
+	p := c.pc * lkSaturationPr(T/c.tc, c.omegaGuess())
+	lo, hi := 0.0, math.Inf(1)
+	for it := 1; it <= satMaxIter; it++ {
+		res, err := c.Solve(T, p)
+		if err != nil {
+			return Saturation{}, err
+		}
+
+		var next float64
+		switch {
+		case res.NRoots == 3:
+			vl, vv := res.Volumes[0], res.Volumes[2]
+			g := res.LnPhi[0] - res.LnPhi[2]
+			if math.Abs(g) < satTol {
+				return Saturation{P: p, VL: vl, VV: vv, LnPhi: res.LnPhi[2], Iterations: it}, nil
+			}
+			// g > 0: the liquid is the less stable phase, so P < Psat
+			if g > 0 {
+				lo = p
+			} else {
+				hi = p
+			}
+			next = p * math.Exp(-g/(res.Z[0]-res.Z[2]))
+		case res.NRoots > 0 && res.Volumes[0] < c.vc:
+			// only the liquid root: above the vapour spinodal
+			hi = p
+		case res.NRoots > 0:
+			// only the vapour root: below the liquid spinodal
+			lo = p
+		default:
+			return Saturation{}, ErrNoPhysicalRoot
+		}
+
+		if !math.IsInf(hi, 1) && hi-lo <= satTol*hi && res.NRoots != 3 {
+			// The bracket closed without a three-root region: the EOS's
+			// own critical point, which can differ slightly from Tc, lies
+			// below T
+			return Saturation{}, &InvalidInputError{Field: "T", Value: T, Constraint: "below the critical temperature of the EOS"}
+		}
+		if !(next > lo && next < hi) {
+			if math.IsInf(hi, 1) {
+				next = 2 * p
+			} else if lo == 0 {
+				next = 0.5 * hi
+			} else {
+				next = math.Sqrt(lo * hi)
+			}
+		}
+		p = next
+	}
+	return Saturation{}, fmt.Errorf("%w: saturation pressure at T = %g", ErrNoConvergence, T)
+}
+
+// omegaGuess estimates ω from the EOS's own α at Tr = 0.7, for the
+// initial vapour pressure guess only; the linear part of the Soave κ(ω)
+// is close enough whatever the alpha function
+func (c *Compiled) omegaGuess() float64 {
+	alpha, _ := c.alpha(0.7)
+	kappa := (math.Sqrt(alpha) - 1) / (1 - math.Sqrt(0.7))
+	return (kappa - 0.480) / 1.574
+}
@@ -8,64 +8,267 @@ import (
 	"slices"
 )
 
+// CubicRoot is a root of a cubic together with an estimate of its accuracy
+type CubicRoot struct {
+	Value complex128
+	// Residual is the relative residual of the root,
+	// |p(x)| / sum|c_i||x|^i for the monic (normalised) cubic: the backward
+	// error, a few machine epsilons for a well-computed root
+	Residual float64
+	// Cond is the relative condition number of the root,
+	// sum|c_i||x|^i / (|x||p'(x)|). The relative error of Value is roughly
+	// Cond times machine epsilon; it is +Inf at a multiple root.
+	Cond float64
+}
+
 // SolveCubic solves ax^3 + bx^2 + cx + d = 0
-// Returns all 3 roots (possibly complex).
+// Returns all 3 roots (possibly complex), real roots first.
 func SolveCubic(a, b, c, d float64) ([3]complex128, error) {
-	if a == 0 {
-		return [3]complex128{}, errors.New("equation provided is not cubic (a = 0)")
+	if err := checkCubic(a, b, c, d); err != nil {
+		return [3]complex128{}, err
 	}
+	b, c, d, k := scaleCubic(a, b, c, d)
+	roots := solveMonic(b, c, d)
+	for i, z := range roots {
+		var err error
+		if roots[i], err = unscale(z, k); err != nil {
+			return [3]complex128{}, err
+		}
+	}
+	return roots, nil
+}
 
-	// 1. Normalize coefficients
-	b /= a
-	c /= a
-	d /= a
+// SolveCubicRoots solves ax^3 + bx^2 + cx + d = 0 and reports a residual
+// and condition estimate for every root.
+//
+// The cubic is first rescaled by powers of two, x = 2^k y, so that its
+// monic coefficients are of order one whatever the magnitude of a, b, c
+// and d. One real root is then found in closed form using real cube roots
+// and a trigonometric or hyperbolic formula chosen by the sign of the
+// discriminant, so no branch relies on complex arithmetic. It is polished
+// with Newton's method and divided out; the remaining quadratic is solved
+// in a cancellation-free form and its real roots are polished against the
+// cubic. A root too large for a float64 is an error.
+func SolveCubicRoots(a, b, c, d float64) ([3]CubicRoot, error) {
+	if err := checkCubic(a, b, c, d); err != nil {
+		return [3]CubicRoot{}, err
+	}
+
+	// Monic form y^3 + by^2 + cy + d in the scaled variable
+	b, c, d, k := scaleCubic(a, b, c, d)
+
+	var roots [3]CubicRoot
+	for i, z := range solveMonic(b, c, d) {
+		var err error
+		if roots[i].Value, err = unscale(z, k); err != nil {
+			return [3]CubicRoot{}, err
+		}
+		roots[i].Residual, roots[i].Cond = rootQuality(b, c, d, z)
+	}
+	return roots, nil
+}
 
-	// 2. Depressed cubic: y^3 + py + q = 0
-	p := c - b*b/3
-	q := 2*b*b*b/27 - b*c/3 + d
+func checkCubic(a, b, c, d float64) error {
+	if !isFinite(a) || !isFinite(b) || !isFinite(c) || !isFinite(d) {
+		return errors.Join(finite("a", a), finite("b", b), finite("c", c), finite("d", d))
+	}
+	if a == 0 {
+		return ErrNotCubic
+	}
+	return nil
+}
 
-	// 3. Discriminant
-	delta := (q*q)/4 + (p*p*p)/27
+// scaleCubic returns the monic cubic y^3 + by^2 + cy + d whose roots are
+// those of ax^3 + bx^2 + cx + d divided by 2^k. k is chosen so that
+// |b|, |c|^(1/2) and |d|^(1/3) are at most 2; the coefficients are built
+// from mantissas and exponents, so nothing overflows and only coefficients
+// negligible beside the others can underflow.
+func scaleCubic(a, b, c, d float64) (float64, float64, float64, int) {
+	fa, ea := math.Frexp(a)
+	coef := [3]float64{b, c, d}
+	var fs [3]float64
+	var es [3]int
+	k, set := 0, false
+	for i, v := range coef {
+		if v == 0 {
+			continue
+		}
+		fs[i], es[i] = math.Frexp(v)
+		// |v/a| < 2^(es-ea+1), so its (i+1)-th root is below 2^k·2
+		if ki := int(math.Ceil(float64(es[i]-ea) / float64(i+1))); !set || ki > k {
+			k, set = ki, true
+		}
+	}
+	for i := range coef {
+		if coef[i] != 0 {
+			coef[i] = math.Ldexp(fs[i]/fa, es[i]-ea-(i+1)*k)
+		}
+	}
+	return coef[0], coef[1], coef[2], k
+}
 
-	// 4. Cube roots of unity
-	omega := complex(-0.5, math.Sqrt(3)/2)
-	omega2 := complex(-0.5, -math.Sqrt(3)/2)
+// unscale returns the root 2^k z of the original cubic
+func unscale(z complex128, k int) (complex128, error) {
+	x := complex(math.Ldexp(real(z), k), math.Ldexp(imag(z), k))
+	if !isFinite(real(x)) || !isFinite(imag(x)) {
+		return 0, fmt.Errorf("%w: a root of the cubic is outside the float64 range", ErrInvalidInput)
+	}
+	return x, nil
+}
 
-	var roots [3]complex128
+// solveMonic solves x^3 + bx^2 + cx + d = 0, real roots first
+func solveMonic(b, c, d float64) [3]complex128 {
+	x1 := polish(b, c, d, realCubicRoot(b, c, d))
 
-	if delta >= 0 {
-		// One real root and two complex
-		u := cmplx.Pow(complex(-q/2+math.Sqrt(delta), 0), 1.0/3)
-		v := cmplx.Pow(complex(-q/2-math.Sqrt(delta), 0), 1.0/3)
+	// Deflate: x^3 + bx^2 + cx + d = (x - x1)(x^2 + ex + f). The
+	// backward recurrence f = -d/x1 is accurate to rounding; e is taken
+	// from whichever recurrence suffers less cancellation.
+	e := b + x1
+	f := c + x1*e
+	if x1 != 0 {
+		f = -d / x1
+		if back := (f - c) / x1; (math.Abs(f)+math.Abs(c))/math.Abs(x1) < math.Abs(b)+math.Abs(x1) {
+			e = back
+		}
+	}
 
-		y1 := u + v
-		y2 := u*omega + v*omega2
-		y3 := u*omega2 + v*omega
+	roots := [3]complex128{complex(x1, 0)}
 
-		shift := complex(b/3, 0)
-		roots[0] = y1 - shift
-		roots[1] = y2 - shift
-		roots[2] = y3 - shift
+	disc := e*e - 4*f
+	if disc >= 0 {
+		// q = -(e + sign(e)sqrt(disc))/2 avoids subtracting close numbers
+		q := -0.5 * (e + math.Copysign(math.Sqrt(disc), e))
+		x2, x3 := q, 0.0
+		if q != 0 {
+			x3 = f / q
+		}
+		x2, x3 = polish(b, c, d, x2), polish(b, c, d, x3)
+		if x3 > x2 {
+			x2, x3 = x3, x2
+		}
+		roots[1] = complex(x2, 0)
+		roots[2] = complex(x3, 0)
 	} else {
-		// Three real roots
-		r := math.Sqrt(-p * p * p / 27)
-		phi := math.Acos(-q / (2 * math.Sqrt(-(p*p*p)/27)))
-		t := 2 * math.Cbrt(r)
+		z := polishComplex(b, c, d, complex(-e/2, math.Sqrt(-disc)/2))
+		roots[1] = z
+		roots[2] = cmplx.Conj(z)
+	}
+	return roots
+}
 
-		y1 := complex(t*math.Cos(phi/3), 0)
-		y2 := complex(t*math.Cos((phi+2*math.Pi)/3), 0)
-		y3 := complex(t*math.Cos((phi+4*math.Pi)/3), 0)
+// realCubicRoot returns one real root of the monic cubic x^3 + bx^2 + cx + d
+// from its depressed form y^3 + py + q = 0 with x = y - b/3
+func realCubicRoot(b, c, d float64) float64 {
+	shift := b / 3
+	p := c - b*shift
+	q := (2*b*b/27-c/3)*b + d
 
-		shift := complex(b/3, 0)
-		roots[0] = y1 - shift
-		roots[1] = y2 - shift
-		roots[2] = y3 - shift
+	m := math.Sqrt(math.Abs(p) / 3)
+	switch {
+	case p == 0 || math.Abs(q) > 0x1p80*m*m*m:
+		// p is negligible beside q (m^3 may even underflow): y^3 = -q to
+		// within rounding, and t below would overflow
+		return math.Cbrt(-q) - shift
+	case p < 0:
+		// t = cos(3θ) or cosh(3θ) depending on its magnitude
+		t := -q / (2 * m * m * m)
+		if math.Abs(t) <= 1 {
+			// Three real roots: take the one of largest magnitude,
+			// which is the best conditioned for deflation
+			theta := math.Acos(t) / 3
+			x := 2*m*math.Cos(theta) - shift
+			for k := 1.0; k <= 2; k++ {
+				if alt := 2*m*math.Cos(theta-2*k*math.Pi/3) - shift; math.Abs(alt) > math.Abs(x) {
+					x = alt
+				}
+			}
+			return x
+		}
+		// One real root, hyperbolic cosine branch
+		y := 2 * m * math.Cosh(math.Acosh(math.Abs(t))/3)
+		return math.Copysign(y, t) - shift
+	default:
+		// One real root, hyperbolic sine branch
+		t := -q / (2 * m * m * m)
+		return 2*m*math.Sinh(math.Asinh(t)/3) - shift
 	}
+}
 
-	return roots, nil
+// polish refines a real root of x^3 + bx^2 + cx + d with Newton's method,
+// keeping the iterate with the smallest residual
+func polish(b, c, d, x float64) float64 {
+	best := math.Abs(((x+b)*x+c)*x + d)
+	for range 8 {
+		if best == 0 {
+			break
+		}
+		fx := ((x+b)*x+c)*x + d
+		dfx := (3*x+2*b)*x + c
+		if dfx == 0 {
+			break
+		}
+		next := x - fx/dfx
+		r := math.Abs(((next+b)*next+c)*next + d)
+		if r >= best {
+			break
+		}
+		x, best = next, r
+	}
+	return x
+}
+
+// polishComplex is polish for a complex root
+func polishComplex(b, c, d float64, z complex128) complex128 {
+	cb, cc, cd := complex(b, 0), complex(c, 0), complex(d, 0)
+	best := cmplx.Abs(((z+cb)*z+cc)*z + cd)
+	for range 8 {
+		if best == 0 {
+			break
+		}
+		fz := ((z+cb)*z+cc)*z + cd
+		dfz := (3*z+2*cb)*z + cc
+		if dfz == 0 {
+			break
+		}
+		next := z - fz/dfz
+		r := cmplx.Abs(((next+cb)*next+cc)*next + cd)
+		if r >= best {
+			break
+		}
+		z, best = next, r
+	}
+	return z
+}
+
+// rootQuality evaluates the relative residual and condition number of z
+// as a root of x^3 + bx^2 + cx + d. Both are unchanged by the scaling of
+// scaleCubic.
+func rootQuality(b, c, d float64, z complex128) (residual, cond float64) {
+	cb, cc, cd := complex(b, 0), complex(c, 0), complex(d, 0)
+	residual = cmplx.Abs(((z+cb)*z+cc)*z + cd)
+	deriv := cmplx.Abs((3*z+2*cb)*z + cc)
+	r := cmplx.Abs(z)
+	scale := ((r+math.Abs(b))*r+math.Abs(c))*r + math.Abs(d)
+	if scale == 0 {
+		// z = 0 is an exact root of x^3
+		return 0, math.Inf(1)
+	}
+	residual /= scale
+	if r == 0 {
+		// relative conditioning is meaningless at 0; use the absolute form
+		r = 1
+	}
+	if deriv == 0 {
+		return residual, math.Inf(1)
+	}
+	return residual, scale / (r * deriv)
 }
 
 // ResultPrinter prints physically meaningfull solutions
+//
+// Deprecated: ResultPrinter only sees the raw roots and cannot tell
+// liquid from vapour or reject roots below b. Use Solve and print the
+// returned Result instead.
 func ResultPrinter(c [3]complex128) {
 	const eps = 1e-9
 	fs := make([]float64, 0, 3)
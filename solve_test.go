@@ -0,0 +1,196 @@
+package cubiceos
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// companionEigenvalues returns the eigenvalues of the companion matrix of
+// x^3 + bx^2 + cx + d by shifted QR iteration, as an independent
+// reference for the cubic solver
+func companionEigenvalues(b, c, d float64) [3]complex128 {
+	h := [3][3]complex128{
+		{complex(-b, 0), complex(-c, 0), complex(-d, 0)},
+		{1, 0, 0},
+		{0, 1, 0},
+	}
+	var eig [3]complex128
+	n := 3
+	for iter := 0; n > 1 && iter < 1000; iter++ {
+		k := n - 1
+		if cmplx.Abs(h[k][k-1]) <= 1e-17*(cmplx.Abs(h[k][k])+cmplx.Abs(h[k-1][k-1])) {
+			eig[k] = h[k][k]
+			n--
+			continue
+		}
+		// Wilkinson shift from the trailing 2x2 block, with an
+		// exceptional shift every 11 iterations to break cycles
+		p, q, r, s := h[k-1][k-1], h[k-1][k], h[k][k-1], h[k][k]
+		tr, det := p+s, p*s-q*r
+		disc := cmplx.Sqrt(tr*tr/4 - det)
+		mu := tr/2 + disc
+		if cmplx.Abs(tr/2-disc-s) < cmplx.Abs(mu-s) {
+			mu = tr/2 - disc
+		}
+		if iter%11 == 10 {
+			mu += complex(cmplx.Abs(h[k][k-1]), 0)
+		}
+
+		// H - mu I = QR with Givens rotations, then H = RQ + mu I on the
+		// active n x n block
+		for i := range n {
+			h[i][i] -= mu
+		}
+		var cs, sn [2]complex128
+		for j := 0; j < n-1; j++ {
+			x, y := h[j][j], h[j+1][j]
+			norm := math.Hypot(cmplx.Abs(x), cmplx.Abs(y))
+			if norm == 0 {
+				cs[j], sn[j] = 1, 0
+				continue
+			}
+			cs[j], sn[j] = x/complex(norm, 0), y/complex(norm, 0)
+			for l := j; l < n; l++ {
+				u, v := h[j][l], h[j+1][l]
+				h[j][l] = cmplx.Conj(cs[j])*u + cmplx.Conj(sn[j])*v
+				h[j+1][l] = -sn[j]*u + cs[j]*v
+			}
+		}
+		for j := 0; j < n-1; j++ {
+			for l := 0; l <= min(j+2, n-1); l++ {
+				u, v := h[l][j], h[l][j+1]
+				h[l][j] = u*cs[j] + v*sn[j]
+				h[l][j+1] = -u*cmplx.Conj(sn[j]) + v*cmplx.Conj(cs[j])
+			}
+		}
+		for i := range n {
+			h[i][i] += mu
+		}
+	}
+	for i := range n {
+		eig[i] = h[i][i]
+	}
+	return eig
+}
+
+// checkRoots compares the roots of ax^3 + bx^2 + cx + d with the companion
+// matrix eigenvalues and checks the reported residuals and conditions
+func checkRoots(t *testing.T, a, b, c, d float64, roots [3]CubicRoot) {
+	t.Helper()
+	// Cauchy-type scaling keeps the companion matrix well balanced
+	mb, mc, md := b/a, c/a, d/a
+	s := math.Max(math.Abs(mb), math.Max(math.Sqrt(math.Abs(mc)), math.Cbrt(math.Abs(md))))
+	if s == 0 {
+		s = 1
+	}
+	eig := companionEigenvalues(mb/s, mc/(s*s), md/(s*s*s))
+	var big float64
+	for i := range eig {
+		eig[i] *= complex(s, 0)
+		big = math.Max(big, cmplx.Abs(eig[i]))
+	}
+
+	used := [3]bool{}
+	for _, r := range roots {
+		if math.IsNaN(r.Residual) || math.IsNaN(r.Cond) || r.Cond < 0 {
+			t.Fatalf("(%g, %g, %g, %g): root %v has Residual %g, Cond %g", a, b, c, d, r.Value, r.Residual, r.Cond)
+		}
+		if r.Residual > 1e-13 {
+			t.Errorf("(%g, %g, %g, %g): root %v has relative residual %g", a, b, c, d, r.Value, r.Residual)
+		}
+		// the error Cond promises, with headroom for the reference and a
+		// cap for multiple roots, where both are only good to ε^(1/3)
+		tol := big * math.Min(1e-4, 1e-8+1e3*0x1p-52*r.Cond)
+		best, bestErr := -1, math.Inf(1)
+		for j, e := range eig {
+			if err := cmplx.Abs(r.Value - e); !used[j] && err < bestErr {
+				best, bestErr = j, err
+			}
+		}
+		if bestErr > tol {
+			t.Errorf("(%g, %g, %g, %g): root %v (Cond %g) is %g from the nearest eigenvalue, tolerance %g; eigenvalues %v",
+				a, b, c, d, r.Value, r.Cond, bestErr, tol, eig)
+			continue
+		}
+		used[best] = true
+	}
+}
+
+func FuzzSolveCubicRoots(f *testing.F) {
+	for _, c := range [][4]float64{
+		{1, -6, 11, -6},    // 1, 2, 3
+		{1, -3, 3, -1},     // triple root
+		{1, 0, 0, -1},      // one real root
+		{1, 0, 1, 0},       // 0, ±i
+		{2, -4, 2, 0},      // double root
+		{1, 1e8, -1, -1e8}, // widely spread roots
+		{1e-300, 1, 1, 1},
+		{1, 1e200, 1, 1},
+		{1, 0, -1e-310, 1},
+		{1, 0, 1e-320, 1e-300},
+	} {
+		f.Add(c[0], c[1], c[2], c[3])
+	}
+	f.Fuzz(func(t *testing.T, a, b, c, d float64) {
+		roots, err := SolveCubicRoots(a, b, c, d)
+		if checkCubic(a, b, c, d) != nil {
+			if err == nil {
+				t.Fatalf("(%g, %g, %g, %g): invalid cubic accepted", a, b, c, d)
+			}
+			return
+		}
+		if err != nil {
+			// only roots beyond the float64 range may be refused
+			if q := math.Abs(b / a); q < 1e300 && math.Abs(c/a) < 1e300 && math.Abs(d/a) < 1e300 {
+				t.Fatalf("(%g, %g, %g, %g): %v", a, b, c, d, err)
+			}
+			return
+		}
+		for _, r := range roots {
+			if !isFinite(real(r.Value)) || !isFinite(imag(r.Value)) {
+				t.Fatalf("(%g, %g, %g, %g): root %v with nil error", a, b, c, d, r.Value)
+			}
+		}
+		// the reference needs representable monic coefficients
+		for _, v := range []float64{b, c, d} {
+			if q := math.Abs(v / a); v != 0 && (q > 1e250 || q < 1e-250) {
+				return
+			}
+		}
+		checkRoots(t, a, b, c, d, roots)
+	})
+}
+
+func TestSolveCubicExtremeScales(t *testing.T) {
+	for _, tc := range []struct {
+		a, b, c, d float64
+		root       float64 //a known real root
+	}{
+		{1, 0, -1e-310, 1, -1},
+		{1e-300, 1, 1, 1, -1e300},
+		{1, 1e200, 1, 1, -1e200},
+		{1, 0, 1e-320, 1e-300, -1e-100},
+	} {
+		roots, err := SolveCubicRoots(tc.a, tc.b, tc.c, tc.d)
+		if err != nil {
+			t.Fatalf("(%g, %g, %g, %g): %v", tc.a, tc.b, tc.c, tc.d, err)
+		}
+		found := false
+		for _, r := range roots {
+			if !isFinite(real(r.Value)) || !isFinite(imag(r.Value)) || math.IsNaN(r.Residual) || math.IsNaN(r.Cond) {
+				t.Fatalf("(%g, %g, %g, %g): root %+v", tc.a, tc.b, tc.c, tc.d, r)
+			}
+			if imag(r.Value) == 0 && math.Abs(real(r.Value)-tc.root) <= 1e-12*math.Abs(tc.root) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("(%g, %g, %g, %g): roots %v, want one at %g", tc.a, tc.b, tc.c, tc.d, roots, tc.root)
+		}
+	}
+
+	if _, err := SolveCubicRoots(1e-300, 1e300, 0, 0); err == nil {
+		t.Error("root of -1e600 accepted")
+	}
+}
@@ -11,6 +11,10 @@ func (SRK) Alpha(tr, w float64) float64 {
 	return c * c
 }
 
+func (SRK) compileAlpha(w float64) alphaFunc {
+	return soaveAlpha(0.480 + 1.574*w - 0.716*w*w)
+}
+
 func (SRK) Params() Params {
 	return Params{
 		Sigma:   1,
@@ -0,0 +1,270 @@
+package cubiceos
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// Distribution is an uncertain input value
+type Distribution interface {
+	Sample(r *rand.Rand) float64
+	Mean() float64
+	StdDev() float64
+}
+
+// Normal is a normal distribution with mean Mu and standard deviation
+// Sigma
+type Normal struct {
+	Mu, Sigma float64
+}
+
+func (d Normal) Sample(r *rand.Rand) float64 { return d.Mu + d.Sigma*r.NormFloat64() }
+func (d Normal) Mean() float64               { return d.Mu }
+func (d Normal) StdDev() float64             { return d.Sigma }
+
+// Uniform is a uniform distribution on [Min, Max]
+type Uniform struct {
+	Min, Max float64
+}
+
+func (d Uniform) Sample(r *rand.Rand) float64 { return d.Min + (d.Max-d.Min)*r.Float64() }
+func (d Uniform) Mean() float64               { return 0.5 * (d.Min + d.Max) }
+func (d Uniform) StdDev() float64             { return (d.Max - d.Min) / math.Sqrt(12) }
+
+// Uncertain gives distributions for the inputs of an EOSCfg. A nil field
+// keeps the value of the base configuration.
+type Uncertain struct {
+	T, P, Tc, Pc, W Distribution
+}
+
+// inputs lists the uncertain fields of cfg with their distributions
+func (u Uncertain) inputs(cfg *EOSCfg) []uncertainInput {
+	all := []uncertainInput{{"T", u.T, &cfg.T}, {"P", u.P, &cfg.P}, {"Tc", u.Tc, &cfg.Tc}, {"Pc", u.Pc, &cfg.Pc}, {"W", u.W, &cfg.W}}
+	return slices.DeleteFunc(all, func(in uncertainInput) bool { return in.dist == nil })
+}
+
+type uncertainInput struct {
+	name  string
+	dist  Distribution
+	field *float64
+}
+
+// Properties are the properties of the stable root that uncertainty is
+// reported for
+type Properties struct {
+	V, Z, LnPhi, HR, SR float64
+}
+
+func stableProperties(res Result) (Properties, error) {
+	i := res.Stable
+	if i < 0 {
+		return Properties{}, ErrNoPhysicalRoot
+	}
+	return Properties{V: res.Volumes[i], Z: res.Z[i], LnPhi: res.LnPhi[i], HR: res.HR[i], SR: res.SR[i]}, nil
+}
+
+// Summary describes the sampled distribution of one property
+type Summary struct {
+	N        int
+	Mean, SD float64
+	sorted   []float64
+}
+
+func summarise(x []float64) Summary {
+	s := Summary{N: len(x), sorted: slices.Sorted(slices.Values(x))}
+	for _, v := range x {
+		s.Mean += v / float64(len(x))
+	}
+	if len(x) > 1 {
+		for _, v := range x {
+			s.SD += (v - s.Mean) * (v - s.Mean)
+		}
+		s.SD = math.Sqrt(s.SD / float64(len(x)-1))
+	}
+	return s
+}
+
+// Percentile returns the p-th percentile (0 to 100) of the samples, by
+// linear interpolation between order statistics
+func (s Summary) Percentile(p float64) float64 {
+	if s.N == 0 || math.IsNaN(p) {
+		return math.NaN()
+	}
+	h := math.Max(0, math.Min(1, p/100)) * float64(s.N-1)
+	lo := int(h)
+	if lo == s.N-1 {
+		return s.sorted[lo]
+	}
+	return s.sorted[lo] + (h-float64(lo))*(s.sorted[lo+1]-s.sorted[lo])
+}
+
+// MonteCarloResult summarises the properties of the stable root over the
+// samples that solved
+type MonteCarloResult struct {
+	V, Z, LnPhi, HR, SR Summary
+	Phases              map[Phase]int //samples in each phase
+	Failed              int           //samples that could not be solved, e.g. a drawn Tc <= 0
+}
+
+// MonteCarlo draws n samples of the uncertain inputs of base from a PCG
+// generator seeded with seed, solves them on a pool of workers
+// (GOMAXPROCS when workers <= 0) and summarises the stable root. The
+// samples are drawn in order on one goroutine, so the result depends on
+// the seed only, not on workers.
+func MonteCarlo(ctx context.Context, base EOSCfg, u Uncertain, n int, seed uint64, workers int) (MonteCarloResult, error) {
+	if n <= 0 {
+		return MonteCarloResult{}, &InvalidInputError{Field: "n", Value: float64(n), Constraint: "> 0"}
+	}
+	if base.Type == nil {
+		return MonteCarloResult{}, ErrNoEOSType
+	}
+	samples := func(yield func(EOSCfg) bool) {
+		r := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+		for range n {
+			cfg := base
+			for _, in := range u.inputs(&cfg) {
+				*in.field = in.dist.Sample(r)
+			}
+			if !yield(cfg) {
+				return
+			}
+		}
+	}
+
+	type outcome struct {
+		props Properties
+		phase Phase
+		err   error
+	}
+	var props []Properties
+	out := MonteCarloResult{Phases: map[Phase]int{}}
+	var firstErr error
+	for o := range pipeline(ctx, iter.Seq[EOSCfg](samples), workers, func(_ int, cfg EOSCfg) outcome {
+		res, err := Solve(cfg)
+		if err != nil {
+			return outcome{err: err}
+		}
+		p, err := stableProperties(res)
+		return outcome{props: p, phase: res.Phase, err: err}
+	}) {
+		if o.err != nil {
+			out.Failed++
+			firstErr = cmp.Or(firstErr, o.err)
+			continue
+		}
+		props = append(props, o.props)
+		out.Phases[o.phase]++
+	}
+	if err := ctx.Err(); err != nil {
+		return MonteCarloResult{}, err
+	}
+	if len(props) == 0 {
+		return MonteCarloResult{}, fmt.Errorf("no sample could be solved: %w", firstErr)
+	}
+	col := func(f func(Properties) float64) Summary {
+		x := make([]float64, len(props))
+		for i, p := range props {
+			x[i] = f(p)
+		}
+		return summarise(x)
+	}
+	out.V = col(func(p Properties) float64 { return p.V })
+	out.Z = col(func(p Properties) float64 { return p.Z })
+	out.LnPhi = col(func(p Properties) float64 { return p.LnPhi })
+	out.HR = col(func(p Properties) float64 { return p.HR })
+	out.SR = col(func(p Properties) float64 { return p.SR })
+	return out, nil
+}
+
+// Sensitivity holds the derivatives of the stable root's properties with
+// respect to one input, e.g. Input "Tc" and D.V = ∂V/∂Tc
+type Sensitivity struct {
+	Input string
+	D     Properties
+}
+
+// Sensitivities returns the local sensitivities of the stable root of cfg
+// to T, P, Tc, Pc and ω, by central differences. It fails if the stable
+// root changes phase within the step.
+func Sensitivities(cfg EOSCfg) ([]Sensitivity, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	res, err := Solve(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stableProperties(res); err != nil {
+		return nil, err
+	}
+	all := Uncertain{T: Normal{}, P: Normal{}, Tc: Normal{}, Pc: Normal{}, W: Normal{}}
+	var out []Sensitivity
+	for _, in := range all.inputs(&cfg) {
+		x := *in.field
+		h := 1e-6 * math.Max(math.Abs(x), 1)
+		// root identifies the stable root by phase and whether it is the
+		// smallest volume, to catch a switch between roots
+		type root struct {
+			phase    Phase
+			smallest bool
+		}
+		eval := func(v float64) (Properties, root, error) {
+			*in.field = v
+			defer func() { *in.field = x }()
+			r, err := Solve(cfg)
+			if err != nil {
+				return Properties{}, root{}, err
+			}
+			p, err := stableProperties(r)
+			return p, root{r.Phase, r.Stable == 0}, err
+		}
+		up, ru, err1 := eval(x + h)
+		down, rd, err2 := eval(x - h)
+		if err := errors.Join(err1, err2); err != nil {
+			return nil, fmt.Errorf("sensitivity to %s: %w", in.name, err)
+		}
+		if ru != rd {
+			return nil, fmt.Errorf("sensitivity to %s: the stable root changes within the step", in.name)
+		}
+		d := func(a, b float64) float64 { return (a - b) / (2 * h) }
+		out = append(out, Sensitivity{Input: in.name, D: Properties{
+			V: d(up.V, down.V), Z: d(up.Z, down.Z), LnPhi: d(up.LnPhi, down.LnPhi),
+			HR: d(up.HR, down.HR), SR: d(up.SR, down.SR),
+		}})
+	}
+	return out, nil
+}
+
+// Propagate estimates the standard deviations of the stable root's
+// properties to first order from the local sensitivities and the
+// standard deviations of the uncertain inputs, treated as independent.
+// It is much cheaper than MonteCarlo but misses non-linearity and phase
+// changes within the spread of the inputs.
+func Propagate(base EOSCfg, u Uncertain) (Properties, error) {
+	cfg := base
+	sd := map[string]float64{}
+	for _, in := range u.inputs(&cfg) {
+		*in.field = in.dist.Mean()
+		sd[in.name] = in.dist.StdDev()
+	}
+	sens, err := Sensitivities(cfg)
+	if err != nil {
+		return Properties{}, err
+	}
+	var v Properties
+	for _, s := range sens {
+		w := sd[s.Input]
+		v.V += math.Pow(s.D.V*w, 2)
+		v.Z += math.Pow(s.D.Z*w, 2)
+		v.LnPhi += math.Pow(s.D.LnPhi*w, 2)
+		v.HR += math.Pow(s.D.HR*w, 2)
+		v.SR += math.Pow(s.D.SR*w, 2)
+	}
+	return Properties{V: math.Sqrt(v.V), Z: math.Sqrt(v.Z), LnPhi: math.Sqrt(v.LnPhi), HR: math.Sqrt(v.HR), SR: math.Sqrt(v.SR)}, nil
+}
@@ -0,0 +1,123 @@
+package cubiceos
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMonteCarloWorkers(t *testing.T) {
+	u := Uncertain{
+		T:  Normal{Mu: 300, Sigma: 2},
+		Tc: Normal{Mu: 369.8, Sigma: 1},
+		W:  Uniform{Min: 0.14, Max: 0.16},
+	}
+	one, err := MonteCarlo(context.Background(), propaneCfg, u, 2000, 42, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if one.V.N+one.Failed != 2000 {
+		t.Errorf("%d samples solved and %d failed, want 2000 in all", one.V.N, one.Failed)
+	}
+	eight, err := MonteCarlo(context.Background(), propaneCfg, u, 2000, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(one, eight) {
+		t.Errorf("results differ with 1 and 8 workers:\n%+v\n%+v", one, eight)
+	}
+}
+
+func TestSensitivities(t *testing.T) {
+	cfg := propaneCfg
+	cfg.P = 5 // vapour, clear of saturation
+	sens, err := Sensitivities(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, err := Solve(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sens) != 5 {
+		t.Fatalf("%d sensitivities, want 5", len(sens))
+	}
+	for _, s := range sens {
+		// a coarser central difference of Solve
+		c := cfg
+		field := map[string]*float64{"T": &c.T, "P": &c.P, "Tc": &c.Tc, "Pc": &c.Pc, "W": &c.W}[s.Input]
+		x := *field
+		h := 1e-4 * math.Max(math.Abs(x), 1)
+		*field = x + h
+		up, err := Solve(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		*field = x - h
+		down, err := Solve(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i := base.Stable
+		for _, p := range []struct {
+			name      string
+			got, u, d float64
+		}{
+			{"V", s.D.V, up.Volumes[i], down.Volumes[i]},
+			{"ln φ", s.D.LnPhi, up.LnPhi[i], down.LnPhi[i]},
+			{"HR", s.D.HR, up.HR[i], down.HR[i]},
+		} {
+			want := (p.u - p.d) / (2 * h)
+			if math.Abs(p.got-want) > 1e-4*math.Abs(want)+1e-12 {
+				t.Errorf("∂%s/∂%s = %g, finite difference %g", p.name, s.Input, p.got, want)
+			}
+		}
+		// and the exact ∂V/∂P of the EOS
+		if s.Input == "P" {
+			v := base.Volumes[i]
+			want := 1 / dPdV(cfg.Type.Params(), base.A, base.B, cfg.T, cfg.R, v)
+			if math.Abs(s.D.V/want-1) > 1e-6 {
+				t.Errorf("∂V/∂P = %g, want 1/(∂P/∂V) = %g", s.D.V, want)
+			}
+		}
+	}
+}
+
+func TestPropagateMatchesMonteCarlo(t *testing.T) {
+	// for small spreads the first-order estimate matches the sampled SD;
+	// the sampling error alone is about 1% for 5000 samples
+	cfg := propaneCfg
+	cfg.P = 5
+	u := Uncertain{
+		T:  Normal{Mu: 300, Sigma: 0.2},
+		P:  Normal{Mu: 5, Sigma: 0.02},
+		Tc: Normal{Mu: 369.8, Sigma: 0.2},
+		W:  Normal{Mu: 0.152, Sigma: 0.001},
+	}
+	sd, err := Propagate(cfg, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc, err := MonteCarlo(context.Background(), cfg, u, 5000, 7, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mc.Failed != 0 || mc.Phases[PhaseTwoRoot] != 5000 {
+		t.Fatalf("samples: %d failed, phases %v", mc.Failed, mc.Phases)
+	}
+	for _, p := range []struct {
+		name       string
+		first, got float64
+	}{
+		{"V", sd.V, mc.V.SD},
+		{"Z", sd.Z, mc.Z.SD},
+		{"ln φ", sd.LnPhi, mc.LnPhi.SD},
+		{"HR", sd.HR, mc.HR.SD},
+		{"SR", sd.SR, mc.SR.SD},
+	} {
+		if math.Abs(p.got/p.first-1) > 0.05 {
+			t.Errorf("SD of %s: Propagate %g, MonteCarlo %g", p.name, p.first, p.got)
+		}
+	}
+}
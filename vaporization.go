@@ -0,0 +1,85 @@
+package cubiceos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Vaporization is the enthalpy of vaporization of a cubic EOS at one
+// temperature, by two thermodynamically equivalent routes. For a
+// consistent EOS, alpha function and saturation solver the two agree to
+// the accuracy of the numerical dPsat/dT.
+type Vaporization struct {
+	T, P      float64 //saturation temperature and vapour pressure
+	VL, VV    float64 //saturated liquid and vapour volumes
+	DPdT      float64 //dPsat/dT, by central differences
+	Residual  float64 //H^R(vapour) - H^R(liquid)
+	Clapeyron float64 //T (VV - VL) dPsat/dT
+	// Watson is Residual at Tr = 0.7 scaled to T by the Watson correlation,
+	// a check on the temperature dependence of the EOS's ΔHvap
+	Watson float64
+}
+
+// Deviation returns the relative difference of the Clapeyron route from
+// the residual enthalpy route, in %
+func (v Vaporization) Deviation() float64 {
+	return 100 * (v.Clapeyron - v.Residual) / v.Residual
+}
+
+// Vaporization returns the enthalpy of vaporization at T < Tc, in the
+// energy units implied by R
+func (c *Compiled) Vaporization(T float64) (Vaporization, error) {
+	sat, err := c.Saturation(T)
+	if err != nil {
+		return Vaporization{}, err
+	}
+	v := Vaporization{T: T, P: sat.P, VL: sat.VL, VV: sat.VV}
+	v.Residual = c.ResidualEnthalpy(T, sat.VV) - c.ResidualEnthalpy(T, sat.VL)
+
+	// the step is kept inside the two-phase region close to Tc
+	h := math.Min(1e-4*T, 0.25*(c.tc-T))
+	up, err1 := c.Saturation(T + h)
+	down, err2 := c.Saturation(T - h)
+	if err := errors.Join(err1, err2); err != nil {
+		return Vaporization{}, fmt.Errorf("dPsat/dT at T = %g: %w", T, err)
+	}
+	v.DPdT = (up.P - down.P) / (2 * h)
+	v.Clapeyron = T * (sat.VV - sat.VL) * v.DPdT
+
+	tRef := 0.7 * c.tc
+	ref := v.Residual
+	if T != tRef {
+		s, err := c.Saturation(tRef)
+		if err != nil {
+			return Vaporization{}, fmt.Errorf("Watson reference at Tr = 0.7: %w", err)
+		}
+		ref = c.ResidualEnthalpy(tRef, s.VV) - c.ResidualEnthalpy(tRef, s.VL)
+	}
+	v.Watson, _ = Watson(T, c.tc, tRef, ref)
+	return v, nil
+}
+
+// Vaporization compiles cfg and returns the enthalpy of vaporization at
+// cfg.T; cfg.P is not used
+func (cfg EOSCfg) Vaporization() (Vaporization, error) {
+	c, err := Compile(cfg.Type, cfg.Tc, cfg.Pc, cfg.W, cfg.R)
+	if err != nil {
+		return Vaporization{}, err
+	}
+	return c.Vaporization(cfg.T)
+}
+
+// Watson scales an enthalpy of vaporization HRef known at TRef to T with
+// the Watson correlation, ΔH = HRef [(Tc - T)/(Tc - TRef)]^0.38. Both
+// temperatures must be below Tc.
+func Watson(T, Tc, TRef, HRef float64) (float64, error) {
+	err := errors.Join(positive("T", T), positive("Tc", Tc), positive("TRef", TRef), finite("HRef", HRef))
+	if err != nil {
+		return 0, err
+	}
+	if err := errors.Join(subcritical("T", T, Tc), subcritical("TRef", TRef, Tc)); err != nil {
+		return 0, err
+	}
+	return HRef * math.Pow((Tc-T)/(Tc-TRef), 0.38), nil
+}
@@ -0,0 +1,54 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestVaporizationConsistency(t *testing.T) {
+	for _, eos := range []EOSType{PR{}, SRK{}} {
+		c, err := Compile(eos, propaneCfg.Tc, propaneCfg.Pc, propaneCfg.W, propaneCfg.R)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k := range 10 {
+			tr := 0.5 + 0.05*float64(k)
+			v, err := c.Vaporization(tr * propaneCfg.Tc)
+			if err != nil {
+				t.Fatalf("%s, Tr = %g: %v", c.Name(), tr, err)
+			}
+			if !(v.Residual > 0) || math.Abs(v.Deviation()) > 1e-4 {
+				t.Errorf("%s, Tr = %g: ΔH residual %g, Clapeyron %g (%g%%)", c.Name(), tr, v.Residual, v.Clapeyron, v.Deviation())
+			}
+		}
+		// Watson is anchored at Tr = 0.7
+		v, err := c.Vaporization(0.7 * propaneCfg.Tc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(v.Watson-v.Residual) > 1e-9*v.Residual {
+			t.Errorf("%s: Watson %g at its reference, residual route %g", c.Name(), v.Watson, v.Residual)
+		}
+		if _, err := c.Vaporization(propaneCfg.Tc); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s at Tc: %v, want ErrInvalidInput", c.Name(), err)
+		}
+	}
+}
+
+func TestWatson(t *testing.T) {
+	// water, 2257 J/g at the normal boiling point scaled to 25 °C:
+	// 2257 (348.95/273.95)^0.38 = 2474.38 J/g
+	h, err := Watson(298.15, 647.1, 373.15, 2257)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(h-2474.38) > 0.01 {
+		t.Errorf("ΔH(298.15 K) = %g J/g, want 2474.38", h)
+	}
+	for _, c := range [][2]float64{{647.1, 373.15}, {700, 373.15}, {298.15, 647.1}} {
+		if _, err := Watson(c[0], 647.1, c[1], 2257); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("T = %g, TRef = %g: %v, want ErrInvalidInput", c[0], c[1], err)
+		}
+	}
+}
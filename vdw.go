@@ -6,6 +6,10 @@ func (VdW) Alpha(tr, w float64) float64 {
 	return 1.0
 }
 
+func (VdW) compileAlpha(w float64) alphaFunc {
+	return func(tr float64) (float64, float64) { return 1, 0 }
+}
+
 func (VdW) Params() Params {
 	return Params{
 		Sigma:   0,
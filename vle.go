@@ -0,0 +1,502 @@
+package cubiceos
+
+import (
+	"fmt"
+	"math"
+)
+
+// GammaPhi is the γ–φ (modified Raoult's law) formulation of vapour–liquid
+// equilibrium,
+//
+//	y_i Φ_i P = x_i γ_i P_i^sat
+//	Φ_i = φ̂_i^V/φ_i^sat exp[-V_i^L (P - P_i^sat)/RT]
+//
+// with γ_i from Liquid and everything else (vapour φ̂_i, pure-component
+// P^sat, φ^sat and saturated liquid volume for the Poynting factor) from
+// the cubic EOS of Vapour. Every component must be below its critical
+// temperature.
+type GammaPhi struct {
+	Vapour *Mixture
+	Liquid GEModel //nil for an ideal liquid solution
+}
+
+// VLEResult is a converged bubble or dew point
+type VLEResult struct {
+	T          float64
+	P          float64
+	X          []float64 //liquid mole fractions
+	Y          []float64 //vapour mole fractions
+	Gamma      []float64 //liquid activity coefficients
+	Phi        []float64 //Φ_i, including the Poynting factor
+	PSat       []float64 //pure-component vapour pressures at T
+	Iterations int
+}
+
+const (
+	vleMaxIter = 200
+	vleTol     = 1e-10
+	// bubbleRetreats bounds the steps back from the trivial solution
+	bubbleRetreats = 20
+)
+
+// pureSat holds the temperature-only pure-component terms at one T
+type pureSat struct {
+	T     float64
+	pSat  []float64
+	lnPhi []float64 //ln φ_i^sat
+	vl    []float64 //saturated liquid volume
+}
+
+func (g GammaPhi) saturation(T float64) (pureSat, error) {
+	n := g.Vapour.Len()
+	s := pureSat{T: T, pSat: make([]float64, n), lnPhi: make([]float64, n), vl: make([]float64, n)}
+	rt := g.Vapour.r * T
+	for i := range n {
+		sat, err := g.Vapour.pure[i].Saturation(T)
+		if err != nil {
+			return pureSat{}, fmt.Errorf("component %d (%s): %w", i, g.Vapour.comps[i].Name, err)
+		}
+		// apply the component's volume translation, as Mixture.LnPhi does
+		c := g.Vapour.comps[i].C
+		s.pSat[i] = sat.P
+		s.lnPhi[i] = sat.LnPhi - c*sat.P/rt
+		s.vl[i] = sat.VL - c
+	}
+	return s, nil
+}
+
+func (g GammaPhi) gamma(T float64, x []float64) []float64 {
+	out := make([]float64, len(x))
+	if g.Liquid == nil {
+		for i := range out {
+			out[i] = 1
+		}
+		return out
+	}
+	g.Liquid.LnGamma(T, x, out)
+	for i := range out {
+		out[i] = math.Exp(out[i])
+	}
+	return out
+}
+
+// phi returns Φ_i at the saturation temperature of s, P and vapour
+// composition y
+func (g GammaPhi) phi(s pureSat, P float64, y []float64) ([]float64, error) {
+	lnPhi, _, err := g.Vapour.LnPhi(s.T, P, y, PhaseVapour)
+	if err != nil {
+		return nil, err
+	}
+	rt := g.Vapour.r * s.T
+	out := make([]float64, len(y))
+	for i := range out {
+		out[i] = math.Exp(lnPhi[i] - s.lnPhi[i] - s.vl[i]*(P-s.pSat[i])/rt)
+	}
+	return out, nil
+}
+
+func (g GammaPhi) check() error {
+	if g.Vapour == nil {
+		return fmt.Errorf("%w: GammaPhi has no vapour mixture set", ErrInvalidInput)
+	}
+	return nil
+}
+
+// BubbleP returns the bubble pressure and vapour composition of liquid x
+// at T
+func (g GammaPhi) BubbleP(T float64, x []float64) (VLEResult, error) {
+	if err := g.check(); err != nil {
+		return VLEResult{}, err
+	}
+	if err := positive("T", T); err != nil {
+		return VLEResult{}, err
+	}
+	x, err := g.Vapour.composition(x)
+	if err != nil {
+		return VLEResult{}, err
+	}
+	s, err := g.saturation(T)
+	if err != nil {
+		return VLEResult{}, err
+	}
+	return g.bubbleP(s, x)
+}
+
+func (g GammaPhi) bubbleP(s pureSat, x []float64) (VLEResult, error) {
+	n := len(x)
+	gamma := g.gamma(s.T, x)
+	phi := ones(n)
+	y := make([]float64, n)
+	P := 0.0
+	for it := 1; it <= vleMaxIter; it++ {
+		next := 0.0
+		for i := range n {
+			next += x[i] * gamma[i] * s.pSat[i] / phi[i]
+		}
+		for i := range n {
+			y[i] = x[i] * gamma[i] * s.pSat[i] / (phi[i] * next)
+		}
+		if math.Abs(next-P) <= vleTol*next {
+			return VLEResult{T: s.T, P: next, X: x, Y: y, Gamma: gamma, Phi: phi, PSat: s.pSat, Iterations: it}, nil
+		}
+		P = next
+		var err error
+		if phi, err = g.phi(s, P, y); err != nil {
+			return VLEResult{}, err
+		}
+	}
+	return VLEResult{}, fmt.Errorf("%w: bubble pressure at T = %g", ErrNoConvergence, s.T)
+}
+
+// DewP returns the dew pressure and liquid composition of vapour y at T
+func (g GammaPhi) DewP(T float64, y []float64) (VLEResult, error) {
+	if err := g.check(); err != nil {
+		return VLEResult{}, err
+	}
+	if err := positive("T", T); err != nil {
+		return VLEResult{}, err
+	}
+	y, err := g.Vapour.composition(y)
+	if err != nil {
+		return VLEResult{}, err
+	}
+	s, err := g.saturation(T)
+	if err != nil {
+		return VLEResult{}, err
+	}
+	return g.dewP(s, y)
+}
+
+func (g GammaPhi) dewP(s pureSat, y []float64) (VLEResult, error) {
+	n := len(y)
+	gamma, phi := ones(n), ones(n)
+	x := make([]float64, n)
+	P := 0.0
+	for it := 1; it <= vleMaxIter; it++ {
+		// inner loop: liquid composition and γ at fixed Φ
+		var next float64
+		for range vleMaxIter {
+			sum := 0.0
+			for i := range n {
+				sum += y[i] * phi[i] / (gamma[i] * s.pSat[i])
+			}
+			next = 1 / sum
+			for i := range n {
+				x[i] = y[i] * phi[i] * next / (gamma[i] * s.pSat[i])
+			}
+			prev := gamma
+			gamma = g.gamma(s.T, x)
+			if maxRelDiff(gamma, prev) <= vleTol {
+				break
+			}
+		}
+		if math.Abs(next-P) <= vleTol*next {
+			return VLEResult{T: s.T, P: next, X: x, Y: y, Gamma: gamma, Phi: phi, PSat: s.pSat, Iterations: it}, nil
+		}
+		P = next
+		var err error
+		if phi, err = g.phi(s, P, y); err != nil {
+			return VLEResult{}, err
+		}
+	}
+	return VLEResult{}, fmt.Errorf("%w: dew pressure at T = %g", ErrNoConvergence, s.T)
+}
+
+// BubbleT returns the bubble temperature and vapour composition of liquid
+// x at P
+func (g GammaPhi) BubbleT(P float64, x []float64) (VLEResult, error) {
+	if err := g.check(); err != nil {
+		return VLEResult{}, err
+	}
+	if err := positive("P", P); err != nil {
+		return VLEResult{}, err
+	}
+	x, err := g.Vapour.composition(x)
+	if err != nil {
+		return VLEResult{}, err
+	}
+	return g.solveT(P, x, g.bubbleP, "bubble")
+}
+
+// DewT returns the dew temperature and liquid composition of vapour y at P
+func (g GammaPhi) DewT(P float64, y []float64) (VLEResult, error) {
+	if err := g.check(); err != nil {
+		return VLEResult{}, err
+	}
+	if err := positive("P", P); err != nil {
+		return VLEResult{}, err
+	}
+	y, err := g.Vapour.composition(y)
+	if err != nil {
+		return VLEResult{}, err
+	}
+	return g.solveT(P, y, g.dewP, "dew")
+}
+
+// solveT finds the T at which the bubble or dew pressure at fixed
+// composition equals P, by secant steps on ln P against 1/T, which is
+// nearly linear
+func (g GammaPhi) solveT(P float64, z []float64, atT func(pureSat, []float64) (VLEResult, error), kind string) (VLEResult, error) {
+	// the first guess mixes the pure saturation temperatures of the
+	// Lee–Kesler correlation; T must stay below every Tc
+	tMax, t0 := math.Inf(1), 0.0
+	for i, c := range g.Vapour.pure {
+		tMax = math.Min(tMax, c.tc)
+		lnPr := math.Log(P / c.pc)
+		t0 += z[i] * c.tc / (1 - lnPr/(5.373*(1+c.omegaGuess())))
+	}
+	t0 = math.Min(t0, 0.95*tMax)
+
+	eval := func(T float64) (VLEResult, float64, error) {
+		s, err := g.saturation(T)
+		if err != nil {
+			return VLEResult{}, 0, err
+		}
+		res, err := atT(s, z)
+		if err != nil {
+			return VLEResult{}, 0, err
+		}
+		return res, math.Log(res.P / P), nil
+	}
+
+	res, f0, err := eval(t0)
+	if err != nil {
+		return VLEResult{}, err
+	}
+	t1 := math.Min(t0*1.02, 0.5*(t0+tMax))
+	for it := 1; it <= vleMaxIter; it++ {
+		var f1 float64
+		if res, f1, err = eval(t1); err != nil {
+			return VLEResult{}, err
+		}
+		if math.Abs(f1) <= vleTol {
+			res.Iterations = it
+			return res, nil
+		}
+		// secant in 1/T
+		u0, u1 := 1/t0, 1/t1
+		u := u1 - f1*(u1-u0)/(f1-f0)
+		next := 1 / u
+		if !(next > 0) || !isFinite(next) {
+			next = 0.5 * t1
+		}
+		if next >= tMax {
+			next = 0.5 * (t1 + tMax)
+		}
+		t0, f0, t1 = t1, f1, next
+	}
+	return VLEResult{}, fmt.Errorf("%w: %s temperature at P = %g", ErrNoConvergence, kind, P)
+}
+
+func ones(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = 1
+	}
+	return out
+}
+
+// maxRelDiff returns max |a_i - b_i|/|a_i|
+func maxRelDiff(a, b []float64) float64 {
+	d := 0.0
+	for i := range a {
+		d = math.Max(d, math.Abs(a[i]-b[i])/math.Abs(a[i]))
+	}
+	return d
+}
+
+// BubbleP returns the bubble pressure and vapour composition of liquid x
+// at T from the EOS alone (φ–φ), with both phases described by the cubic
+// mixture. Gamma, Phi and PSat are left nil.
+func (m *Mixture) BubbleP(T float64, x []float64) (VLEResult, error) {
+	if err := positive("T", T); err != nil {
+		return VLEResult{}, err
+	}
+	x, err := m.composition(x)
+	if err != nil {
+		return VLEResult{}, err
+	}
+	T, P, y := m.wilsonBubble(T, 0, x)
+	return m.bubble(T, P, x, y, true)
+}
+
+// BubbleT returns the bubble temperature and vapour composition of liquid
+// x at P from the EOS alone (φ–φ). Gamma, Phi and PSat are left nil.
+func (m *Mixture) BubbleT(P float64, x []float64) (VLEResult, error) {
+	if err := positive("P", P); err != nil {
+		return VLEResult{}, err
+	}
+	x, err := m.composition(x)
+	if err != nil {
+		return VLEResult{}, err
+	}
+	T, P, y := m.wilsonBubble(0, P, x)
+	return m.bubble(T, P, x, y, false)
+}
+
+// wilsonBubble estimates the bubble pressure (T given, P = 0) or
+// temperature (P given, T = 0) of x, and the vapour composition, from
+// Wilson K-values
+func (m *Mixture) wilsonBubble(T, P float64, x []float64) (float64, float64, []float64) {
+	k := func(T, P float64) ([]float64, float64) {
+		K := make([]float64, len(x))
+		sum := 0.0
+		for i, c := range m.comps {
+			K[i] = c.Pc / P * math.Exp(5.373*(1+c.W)*(1-c.Tc/T))
+			sum += K[i] * x[i]
+		}
+		return K, sum
+	}
+	if P == 0 {
+		// Σ x_i K_i is inversely proportional to P
+		_, P = k(T, 1)
+	} else {
+		// Σ x_i K_i rises with T; bisect in ln T
+		lo, hi := 1.0, 10000.0
+		for range 100 {
+			T = math.Sqrt(lo * hi)
+			if _, sum := k(T, P); sum > 1 {
+				hi = T
+			} else {
+				lo = T
+			}
+		}
+	}
+	K, sum := k(T, P)
+	y := make([]float64, len(x))
+	for i := range y {
+		y[i] = K[i] * x[i] / sum
+	}
+	return T, P, y
+}
+
+// bubble converges a φ–φ bubble point from T, P and vapour composition y
+// by successive substitution on K, updating P (atT) or T so that
+// Σ K_i x_i = 1. The temperature step uses the Wilson slope
+// d ln K_i/d(1/T) = -5.373(1 + ω_i)Tc_i.
+//
+// A start above the bubble point, e.g. a Wilson estimate near the
+// mixture critical point, collapses onto the trivial solution. P is then
+// lowered (or T raised) toward the two-phase side and the iteration
+// restarted from y, with the collapse point as a bound it may not cross.
+//
+// Successive substitution slows down near the critical point, so once it
+// is close the point is finished by Newton's method on the β = 0 envelope
+// equations, with T (atT) or P held fixed.
+func (m *Mixture) bubble(T, P float64, x, y []float64, atT bool) (VLEResult, error) {
+	n := len(x)
+	y0 := y
+	y = append([]float64(nil), y...)
+	bound, retreats, newton := math.NaN(), 0, false
+	for it := 1; it <= vleMaxIter; it++ {
+		lnL, zL, _, err := m.fugacity(T, P, x, PhaseLiquid)
+		if err != nil {
+			return VLEResult{}, err
+		}
+		lnV, zV, _, err := m.fugacity(T, P, y, PhaseVapour)
+		if err != nil {
+			return VLEResult{}, err
+		}
+		next, lnK := make([]float64, n), make([]float64, n)
+		// K = 1 with one volume root for both phases is the trivial
+		// solution; K = 1 alone is an azeotrope. The iteration is stopped
+		// on its way there: a bubble point this close to K = 1 is within a
+		// fraction of a kelvin of the critical point.
+		sum, trivial := 0.0, math.Abs(zL-zV) < 1e-3*zL
+		for i := range n {
+			lnK[i] = lnL[i] - lnV[i]
+			trivial = trivial && math.Abs(lnK[i]) < 1e-3
+			next[i] = x[i] * math.Exp(lnK[i])
+			sum += next[i]
+		}
+		if trivial {
+			if retreats == bubbleRetreats {
+				return VLEResult{}, fmt.Errorf("%w: bubble point collapsed onto the trivial solution K = 1 at T = %g, P = %g; the liquid may be above its critical point",
+					ErrNoConvergence, T, P)
+			}
+			retreats++
+			newton = false
+			copy(y, y0)
+			if atT {
+				bound, P = P, 0.7*P
+			} else {
+				bound, T = T, 1.05*T
+			}
+			continue
+		}
+		for i := range next {
+			next[i] /= sum
+		}
+		lnS := math.Log(sum)
+		if !newton && zV < 3*zL && math.Abs(lnS) < 1e-2 && maxAbsDiff(next, y) < 1e-2 {
+			newton = true
+			if res, ok := m.bubbleNewton(T, P, x, lnK, atT); ok {
+				res.Iterations += it
+				return res, nil
+			}
+		}
+		if math.Abs(lnS) < vleTol && maxAbsDiff(next, y) < vleTol {
+			return VLEResult{T: T, P: P, X: x, Y: next, Iterations: it}, nil
+		}
+		y = next
+		lnS = math.Max(-1, math.Min(1, lnS))
+		if atT {
+			nextP := P * math.Exp(lnS)
+			if nextP >= bound {
+				nextP = math.Sqrt(P * bound)
+			}
+			P = nextP
+			continue
+		}
+		slope := 0.0
+		for i, c := range m.comps {
+			slope += y[i] * 5.373 * (1 + c.W) * c.Tc
+		}
+		nextT := 1 / (1/T + lnS/slope)
+		if nextT <= bound {
+			nextT = math.Sqrt(T * bound)
+		}
+		T = nextT
+	}
+	if atT {
+		return VLEResult{}, fmt.Errorf("%w: bubble pressure at T = %g", ErrNoConvergence, T)
+	}
+	return VLEResult{}, fmt.Errorf("%w: bubble temperature at P = %g", ErrNoConvergence, P)
+}
+
+// bubbleNewton finishes a bubble point from nearby ln K, T and P with
+// Newton's method, reporting false if it fails or reaches the trivial
+// solution
+func (m *Mixture) bubbleNewton(T, P float64, x, lnK []float64, atT bool) (VLEResult, bool) {
+	n := len(x)
+	line := envelopeLine{m: m, z: x}
+	v := append(append([]float64(nil), lnK...), math.Log(T), math.Log(P))
+	s := n + 1
+	if atT {
+		s = n
+	}
+	v, it, err := line.solve(v, s)
+	if err != nil || maxAbs(v[:n]) < 1e-4 {
+		return VLEResult{}, false
+	}
+	_, ys := line.phases(v)
+	T, P = math.Exp(v[n]), math.Exp(v[n+1])
+	y := normalise(ys)
+
+	// the envelope equations take each phase's stable root; the bubble
+	// point needs the liquid root for x and the vapour root for y
+	lnL, _, _, err := m.fugacity(T, P, x, PhaseLiquid)
+	if err != nil {
+		return VLEResult{}, false
+	}
+	lnV, _, _, err := m.fugacity(T, P, y, PhaseVapour)
+	if err != nil {
+		return VLEResult{}, false
+	}
+	for i := range n {
+		if math.Abs(lnL[i]-lnV[i]-v[i]) > 1e-8 {
+			return VLEResult{}, false
+		}
+	}
+	return VLEResult{T: T, P: P, X: x, Y: y, Iterations: it}, true
+}
@@ -0,0 +1,152 @@
+package cubiceos
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBubblePTernary(t *testing.T) {
+	m, z := ternaryPR(t)
+	for _, tc := range []struct{ T, P float64 }{
+		{300, 153.56},
+		{350, 187.14},
+		{400, 191.08},
+	} {
+		res, err := m.BubbleP(tc.T, z)
+		if err != nil {
+			t.Errorf("T = %g: %v", tc.T, err)
+			continue
+		}
+		if math.Abs(res.P-tc.P) > 0.01 {
+			t.Errorf("T = %g: bubble P %g bar, want %g", tc.T, res.P, tc.P)
+		}
+		// equal fugacities in the liquid and a distinct vapour phase
+		lnL, zL, _, err := m.fugacity(res.T, res.P, z, PhaseLiquid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lnV, zV, _, err := m.fugacity(res.T, res.P, res.Y, PhaseVapour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range z {
+			if d := math.Abs(math.Log(z[i]/res.Y[i]) + lnL[i] - lnV[i]); d > 1e-8 {
+				t.Errorf("T = %g: component %d fugacities differ by %g", tc.T, i, d)
+			}
+		}
+		if math.Abs(zL-zV) < 1e-3 {
+			t.Errorf("T = %g: trivial solution, Z = %g for both phases", tc.T, zL)
+		}
+	}
+}
+
+func gammaPhiBinary(t *testing.T, comps []Component) GammaPhi {
+	t.Helper()
+	m, err := NewMixture(PR{}, comps, nil, barCm3R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return GammaPhi{Vapour: m}
+}
+
+func TestGammaPhiRaoultLimit(t *testing.T) {
+	// an ideal liquid well below 1 bar: Φ_i ≈ 1, so P = Σ x_i P_i^sat and
+	// y_i = x_i P_i^sat/P
+	g := gammaPhiBinary(t, []Component{
+		{Name: "n-pentane", Tc: 469.7, Pc: 33.7, W: 0.252},
+		{Name: "n-hexane", Tc: 507.6, Pc: 30.25, W: 0.301},
+	})
+	x := []float64{0.4, 0.6}
+	res, err := g.BubbleP(280, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raoult := x[0]*res.PSat[0] + x[1]*res.PSat[1]
+	if res.P > 1 || math.Abs(res.P/raoult-1) > 5e-3 {
+		t.Errorf("bubble P %g bar, Raoult's law gives %g", res.P, raoult)
+	}
+	for i := range x {
+		if want := x[i] * res.PSat[i] / raoult; math.Abs(res.Y[i]-want) > 5e-3 {
+			t.Errorf("y[%d] = %g, Raoult's law gives %g", i, res.Y[i], want)
+		}
+	}
+	dew, err := g.DewP(280, res.Y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(dew.P/res.P-1) > 1e-8 || math.Abs(dew.X[0]-x[0]) > 1e-8 {
+		t.Errorf("dew point of the bubble vapour: P = %g, x = %v; want %g, %v", dew.P, dew.X, res.P, x)
+	}
+}
+
+func TestGammaPhiPoynting(t *testing.T) {
+	// at 15–25 bar the Poynting factor is a few per cent; Φ_i must be the
+	// vapour φ̂_i/φ_i^sat with the Poynting factor on top, and every result
+	// must satisfy y_i Φ_i P = x_i γ_i P_i^sat
+	g := gammaPhiBinary(t, []Component{
+		{Name: "propane", Tc: 369.8, Pc: 42.48, W: 0.152},
+		{Name: "n-butane", Tc: 425.1, Pc: 37.96, W: 0.2},
+	})
+	z := []float64{0.6, 0.4}
+	bubble, err := g.BubbleP(340, z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dew, err := g.DewP(340, z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bubbleT, err := g.BubbleT(bubble.P, z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dewT, err := g.DewT(dew.P, z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(bubbleT.T-340) > 1e-6 || math.Abs(dewT.T-340) > 1e-6 {
+		t.Errorf("bubble T %g, dew T %g; want 340", bubbleT.T, dewT.T)
+	}
+	for _, tc := range []struct {
+		name string
+		res  VLEResult
+	}{
+		{"BubbleP", bubble},
+		{"DewP", dew},
+		{"BubbleT", bubbleT},
+		{"DewT", dewT},
+	} {
+		res := tc.res
+		rt := barCm3R * res.T
+		lnPhiV, _, err := g.Vapour.LnPhi(res.T, res.P, res.Y, PhaseVapour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, c := range g.Vapour.pure {
+			sat, err := c.Saturation(res.T)
+			if err != nil {
+				t.Fatal(err)
+			}
+			off := math.Exp(lnPhiV[i] - sat.LnPhi)
+			poynting := math.Exp(sat.VL * (res.P - sat.P) / rt)
+			if math.Abs(poynting-1) < 1e-3 {
+				t.Errorf("%s: component %d Poynting factor %g is too close to 1 to test", tc.name, i, poynting)
+			}
+			if got := res.Phi[i] * poynting; math.Abs(got/off-1) > 1e-8 {
+				t.Errorf("%s: component %d Φ·Poynting = %g, want φ̂/φ^sat = %g", tc.name, i, got, off)
+			}
+			lhs := res.Y[i] * res.Phi[i] * res.P
+			rhs := res.X[i] * res.Gamma[i] * res.PSat[i]
+			if math.Abs(lhs/rhs-1) > 1e-8 {
+				t.Errorf("%s: component %d y Φ P = %g, x γ P^sat = %g", tc.name, i, lhs, rhs)
+			}
+		}
+	}
+}
+
+func TestGammaPhiNoVapour(t *testing.T) {
+	if _, err := (GammaPhi{}).BubbleP(300, []float64{1}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("BubbleP without a vapour mixture: %v, want ErrInvalidInput", err)
+	}
+}